@@ -0,0 +1,138 @@
+// Command lazydb-plugin-csv is a reference lazydb plugin that serves a
+// directory of CSV files as a read-only database. Each file is a table named
+// after the file, and the first record holds the column names.
+//
+// Build it into lazydb's plugins directory and add a connection with
+// type = "csv" and path pointing at the directory:
+//
+//	go build -o plugins/lazydb-plugin-csv ./cmd/lazydb-plugin-csv
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/davesavic/lazydb/pkg/plugin"
+)
+
+// selectPattern matches the only statement the plugin understands:
+// SELECT * FROM <table> [LIMIT <n>], where n may be a $1 placeholder.
+var selectPattern = regexp.MustCompile(`(?is)^\s*select\s+\*\s+from\s+"?([\w.-]+)"?(?:\s+limit\s+(\d+|\$\d+))?\s*;?\s*$`)
+
+type csvDatabase struct {
+	dir string
+}
+
+func (c *csvDatabase) Connect(cfg plugin.Config) error {
+	info, err := os.Stat(cfg.Path)
+	if err != nil {
+		return fmt.Errorf("could not open directory: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", cfg.Path)
+	}
+
+	c.dir = cfg.Path
+
+	return nil
+}
+
+func (c *csvDatabase) GetTables() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.csv"))
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]string, 0, len(files))
+	for _, f := range files {
+		tables = append(tables, strings.TrimSuffix(filepath.Base(f), ".csv"))
+	}
+
+	return tables, nil
+}
+
+func (c *csvDatabase) Run(_ context.Context, query string, args []any) (*plugin.Result, error) {
+	match := selectPattern.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("unsupported query: only SELECT * FROM <table> [LIMIT <n>] is supported")
+	}
+
+	limit, err := limitValue(match[2], args)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(c.dir, match[1]+".csv"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown table %q", match[1])
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read table %q: %w", match[1], err)
+	}
+
+	result := &plugin.Result{}
+	if len(records) == 0 {
+		return result, nil
+	}
+
+	result.Columns = records[0]
+	for _, record := range records[1:] {
+		if limit >= 0 && len(result.Rows) >= limit {
+			break
+		}
+
+		row := make([]any, len(record))
+		for i, v := range record {
+			row[i] = v
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+// limitValue returns the number of rows a LIMIT clause allows, reading it
+// from args for a placeholder, or -1 if there is no limit.
+func limitValue(limit string, args []any) (int, error) {
+	if limit == "" {
+		return -1, nil
+	}
+
+	n, ok := strings.CutPrefix(limit, "$")
+	if !ok {
+		return strconv.Atoi(limit)
+	}
+
+	i, _ := strconv.Atoi(n)
+	if i < 1 || i > len(args) {
+		return 0, fmt.Errorf("no argument for %s", limit)
+	}
+
+	switch v := args[i-1].(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	}
+
+	return 0, fmt.Errorf("%s is not a number", limit)
+}
+
+func (c *csvDatabase) Close() error {
+	return nil
+}
+
+func main() {
+	plugin.Serve(&csvDatabase{})
+}
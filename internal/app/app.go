@@ -1,15 +1,21 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/davesavic/lazydb/internal/keybinding"
 	"github.com/davesavic/lazydb/internal/service/config"
 	"github.com/davesavic/lazydb/internal/service/database"
+	"github.com/davesavic/lazydb/internal/service/history"
 	"github.com/davesavic/lazydb/internal/service/message"
 	screenmanager "github.com/davesavic/lazydb/internal/service/screen"
+	"github.com/davesavic/lazydb/internal/service/session"
 	"github.com/davesavic/lazydb/internal/ui/common"
 )
 
@@ -17,27 +23,44 @@ var _ tea.Model = &App{}
 
 // App is the main application struct that holds the state of the application.
 type App struct {
-	keys            *keybinding.Keymap
-	screenManager   *screenmanager.Screen
-	messageManager  *message.Manager
-	configService   *config.Service
-	databaseService database.DatabaseIntegration
+	keys           *keybinding.Keymap
+	screenManager  *screenmanager.Screen
+	messageManager *message.Manager
+	configService  *config.Service
+	sessionManager *session.Manager
+	history        *history.Store
+	// waiting holds the background requests of each session, such as loading
+	// its catalog, that wait for it to be idle: for its running query to
+	// finish, and its result set to be fetched or closed.
+	waiting map[string][]tea.Msg
 }
 
 func NewApp() *App {
 	keys := keybinding.NewKeymap()
 	configService := config.NewService()
-	pgdb := database.NewPostgres()
+	sessionManager := session.NewManager()
+	messageManager := message.NewManager()
+	historyStore := history.NewStore(history.DefaultPath)
+
+	err := database.LoadPlugins("plugins")
+	if err != nil {
+		slog.Error("NewApp", "error", err)
+	}
 
 	return &App{
-		keys:            keys,
-		configService:   configService,
-		databaseService: pgdb,
+		keys:           keys,
+		configService:  configService,
+		sessionManager: sessionManager,
+		messageManager: messageManager,
+		history:        historyStore,
+		waiting:        make(map[string][]tea.Msg),
 		screenManager: screenmanager.NewScreen(&common.ScreenProps{
-			MessageManager:  message.NewManager(),
-			DatabaseService: pgdb,
-			ConfigService:   configService,
-			Keymap:          keys,
+			MessageManager: messageManager,
+			ConfigService:  configService,
+			SessionManager: sessionManager,
+			History:        historyStore,
+			Params:         history.NewParamStore(history.DefaultParamsPath),
+			Keymap:         keys,
 		}),
 	}
 }
@@ -58,38 +81,357 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, a.keys.Quit):
-			return a, tea.Quit
+			if open := a.sessionManager.OpenTransactions(); len(open) > 0 {
+				prompt := fmt.Sprintf("Quit and roll back the open transactions of %s?", strings.Join(open, ", "))
+				return a, a.messageManager.NewConfirmCmd(prompt, message.QuitMsg{})
+			}
+			return a, a.quit()
+		case key.Matches(msg, a.keys.CancelQuery):
+			if s := a.sessionManager.Active(); s != nil && s.CancelQuery() {
+				slog.Debug("App.Update.CancelQuery", "session", s.Name)
+			}
+			return a, nil
+		case key.Matches(msg, a.keys.NextSession):
+			if s := a.sessionManager.Active(); s != nil && s.Transaction().Open {
+				prompt := fmt.Sprintf("%s has an open transaction. Switch sessions anyway?", s.Name)
+				return a, a.messageManager.NewConfirmCmd(prompt, message.NextSessionMsg{})
+			}
+			return a, a.nextSession()
+		case key.Matches(msg, a.keys.ToggleTransactions):
+			s := a.sessionManager.Active()
+			if s == nil {
+				return a, nil
+			}
+
+			err := s.SetManualTransactions(!s.Transaction().Manual)
+			if err != nil {
+				return a, message.NewStatusUpdateCmd("IN TRANSACTION", err.Error())
+			}
+
+			status := "Statements are committed as they run"
+			if s.Transaction().Manual {
+				status = "Statements run in a transaction until committed or rolled back"
+			}
+
+			return a, tea.Batch(
+				a.transactionChangedCmd(s),
+				message.NewStatusUpdateCmd("TRANSACTIONS", status),
+			)
+		case key.Matches(msg, a.keys.Commit), key.Matches(msg, a.keys.Rollback):
+			s := a.sessionManager.Active()
+			if s == nil {
+				return a, nil
+			}
+
+			commit := key.Matches(msg, a.keys.Commit)
+			statements := s.Transaction().Statements
+
+			err := s.EndTransaction(context.Background(), commit)
+			if err != nil {
+				slog.Error("App.Update.EndTransaction", "session", s.Name, "error", err)
+				return a, tea.Batch(
+					a.transactionChangedCmd(s),
+					a.messageManager.NewErrorCmd(err),
+				)
+			}
+
+			status := message.NewStatusUpdateCmd("ROLLED BACK", fmt.Sprintf("%d statements rolled back", statements))
+			if commit {
+				status = message.NewStatusUpdateCmd("COMMITTED", fmt.Sprintf("%d statements committed", statements))
+			}
+
+			return a, tea.Batch(a.transactionChangedCmd(s), status)
 		}
 
+	case message.QuitMsg:
+		return a, a.quit()
+
+	case message.NextSessionMsg:
+		cmds = append(cmds, a.nextSession())
+
 	case message.LoadConnectionMsg:
 		slog.Debug("App.Update.LoadConnectionMsg", "msg", msg)
+
+		// Loading an already open connection switches to its session.
+		if _, ok := a.sessionManager.Get(msg.Name); ok {
+			if s := a.sessionManager.Active(); !msg.Confirmed && s != nil && s.Name != msg.Name && s.Transaction().Open {
+				msg.Confirmed = true
+				prompt := fmt.Sprintf("%s has an open transaction. Switch to %s anyway?", s.Name, msg.Name)
+				return a, a.messageManager.NewConfirmCmd(prompt, msg)
+			}
+
+			err := a.sessionManager.Activate(msg.Name)
+			if err != nil {
+				slog.Error("App.Update.LoadConnectionMsg", "error", err)
+				return a, nil
+			}
+
+			cmds = append(cmds, a.sessionsChangedCmd())
+			break
+		}
+
 		consCfg, err := a.configService.GetConnection(msg.Name)
 		if err != nil {
 			slog.Error("App.Update.LoadConnectionMsg", "error", err)
-			return a, tea.Quit
-			// return a, a.messageManager.NewErrorCmd(err)
+			return a, a.messageManager.NewErrorCmd(err)
+		}
+
+		db, err := database.New(consCfg.Type)
+		if err != nil {
+			slog.Error("App.Update.LoadConnectionMsg", "error", err)
+			return a, a.messageManager.NewErrorCmd(err)
 		}
 
-		err = a.databaseService.Connect(*consCfg)
+		err = db.Connect(*consCfg)
 		if err != nil {
 			slog.Error("App.Update.LoadConnectionMsg", "error", err)
-			return a, tea.Quit
-			// return a, a.messageManager.NewErrorCmd(err)
+			_ = db.Close()
+			return a, a.messageManager.NewErrorCmd(err)
+		}
+
+		a.sessionManager.Open(msg.Name, *consCfg, db)
+
+		cmds = append(cmds, tea.Sequence(
+			a.sessionsChangedCmd(),
+			a.messageManager.NewNewConnectionLoadedCmd(msg.Name),
+		))
+
+	case message.NewConnectionLoadedMsg:
+		cmds = append(cmds, a.messageManager.NewLoadCatalogCmd(msg.Session))
+
+	case message.LoadCatalogMsg:
+		s, ok := a.sessionManager.Get(msg.Session)
+		if !ok {
+			break
+		}
+
+		ctx, ok := s.BeginLoad()
+		if !ok {
+			cmds = append(cmds, a.waitLoad(s, msg))
+			break
+		}
+
+		cmds = append(cmds, a.loadCatalogCmd(ctx, s))
+
+	case message.CatalogLoadedMsg:
+		a.endQuery(msg.Session, nil)
+
+		if msg.Err != nil {
+			slog.Error("App.Update.CatalogLoadedMsg", "session", msg.Session, "error", msg.Err)
+			cmds = append(cmds, message.NewStatusUpdateCmd("COMPLETION", fmt.Sprintf("Could not load completions for %s: %v", msg.Session, msg.Err)))
+			break
+		}
+
+		cmds = append(cmds, message.NewStatusUpdateCmd("COMPLETION", fmt.Sprintf("Loaded completions for %d tables of %s", len(msg.Catalog.Tables), msg.Session)))
+
+	case message.LoadObjectsMsg:
+		s, ok := a.sessionManager.Get(msg.Session)
+		if !ok {
+			break
+		}
+
+		ctx, ok := s.BeginLoad()
+		if !ok {
+			cmds = append(cmds, a.waitLoad(s, msg))
+			break
+		}
+
+		cmds = append(cmds, a.loadObjectsCmd(ctx, s, msg.Path))
+
+	case message.ObjectsLoadedMsg:
+		a.endQuery(msg.Session, nil)
+
+		if msg.Err != nil {
+			slog.Error("App.Update.ObjectsLoadedMsg", "session", msg.Session, "error", msg.Err)
+		}
+
+	case message.LoadStructureMsg:
+		s, ok := a.sessionManager.Get(msg.Session)
+		if !ok {
+			break
+		}
+
+		ctx, ok := s.BeginLoad()
+		if !ok {
+			cmds = append(cmds, a.waitLoad(s, msg))
+			break
+		}
+
+		cmds = append(cmds, a.loadStructureCmd(ctx, s, msg.Schema, msg.Table))
+
+	case message.StructureLoadedMsg:
+		a.endQuery(msg.Session, nil)
+
+		if msg.Err != nil {
+			slog.Error("App.Update.StructureLoadedMsg", "session", msg.Session, "table", msg.Table, "error", msg.Err)
+			cmds = append(cmds, message.NewStatusUpdateCmd("STRUCTURE", fmt.Sprintf("Could not describe %s: %v", msg.Table, msg.Err)))
+		}
+
+	case message.CloseConnectionMsg:
+		slog.Debug("App.Update.CloseConnectionMsg", "msg", msg)
+		s, ok := a.sessionManager.Get(msg.Name)
+		if !ok {
+			return a, nil
 		}
 
-		cmds = append(cmds, a.messageManager.NewNewConnectionLoadedCmd())
+		if !msg.Confirmed && s.Transaction().Open {
+			msg.Confirmed = true
+			prompt := fmt.Sprintf("Close %s and roll back its open transaction?", msg.Name)
+			return a, a.messageManager.NewConfirmCmd(prompt, msg)
+		}
+
+		err := a.sessionManager.Close(msg.Name)
+		if err != nil {
+			slog.Error("App.Update.CloseConnectionMsg", "error", err)
+		}
+
+		cmds = append(cmds, a.sessionsChangedCmd())
+
 	case message.ExecuteQueryMsg:
 		slog.Debug("App.Update.ExecuteQueryMsg", "msg", msg)
-		result, err := a.databaseService.Run(msg.Query)
+		s := a.sessionManager.Active()
+		if msg.Session != "" {
+			s, _ = a.sessionManager.Get(msg.Session)
+		}
+		if s == nil {
+			slog.Debug("App.Update.ExecuteQueryMsg: no session")
+			break
+		}
+
+		if kind := s.Database.Dialect().Classify(msg.Query); s.Config.ReadOnly && kind != database.StatementRead {
+			cmds = append(cmds, message.NewStatusUpdateCmd("READ ONLY", fmt.Sprintf("%s is a read-only connection, %s statements are not run", s.Name, kind)))
+			break
+		}
+
+		if s.Config.ReadOnly && s.Database.Dialect().ChangesSettings(msg.Query) {
+			cmds = append(cmds, message.NewStatusUpdateCmd("READ ONLY", fmt.Sprintf("%s is a read-only connection, statements that change settings are not run", s.Name)))
+			break
+		}
+
+		ctx, ok := s.BeginQuery()
+		if !ok {
+			cmds = append(cmds, message.NewStatusUpdateCmd("BUSY", "A query is already running, cancel it first"))
+			break
+		}
+
+		// Statements that may destroy data are only run once confirmed, and
+		// the rows they affect are estimated first to show what is at stake.
+		if dangers := s.Database.Dialect().Dangers(msg.Query); len(dangers) > 0 && !msg.Confirmed {
+			msg.Session = s.Name
+			cmds = append(cmds, a.estimateDangersCmd(ctx, s, msg, dangers))
+			break
+		}
+
+		err := a.beginStatement(s)
 		if err != nil {
-			slog.Error("App.Update.ExecuteQueryMsg", "error", err)
-			return a, tea.Quit
-			// return a, a.messageManager.NewErrorCmd(err)
+			cmds = append(cmds, sessionErrorCmd(s.Name, err))
+			break
+		}
+
+		cmds = append(cmds,
+			a.transactionChangedCmd(s),
+			a.messageManager.NewQueryStartedCmd(s.Name, msg.Query, msg.Args, msg.Target),
+			a.runQueryCmd(ctx, s, msg.Query, msg.Args, msg.Remaining),
+		)
+
+	case message.DangerousQueryMsg:
+		a.endQuery(msg.Session, nil)
+
+		prompt := fmt.Sprintf("This query may destroy data:\n\n%s\n\nRun it anyway?", strings.Join(msg.Dangers, "\n"))
+		msg.Query.Confirmed = true
+		cmds = append(cmds, a.messageManager.NewConfirmCmd(prompt, msg.Query))
+
+	case message.ApplyChangesMsg:
+		slog.Debug("App.Update.ApplyChangesMsg", "session", msg.Session, "statements", len(msg.Statements))
+		s, ok := a.sessionManager.Get(msg.Session)
+		if !ok {
+			break
 		}
 
-		cmds = append(cmds, a.messageManager.NewQueryExecutedCmd(result))
+		if s.Config.ReadOnly {
+			cmds = append(cmds, sessionErrorCmd(s.Name, fmt.Errorf("%s is a read-only connection", s.Name)))
+			break
+		}
+
+		// The open result set is closed first, as the connection cannot
+		// execute statements while rows are still being read.
+		ctx, ok := s.BeginQuery()
+		if !ok {
+			cmds = append(cmds, message.NewStatusUpdateCmd("BUSY", "A query is already running, cancel it first"))
+			break
+		}
+
+		err := a.beginStatement(s)
+		if err != nil {
+			cmds = append(cmds, sessionErrorCmd(s.Name, err))
+			break
+		}
+
+		cmds = append(cmds,
+			a.transactionChangedCmd(s),
+			a.applyChangesCmd(ctx, s, msg.Statements),
+		)
+
+	case message.ChangesAppliedMsg:
+		a.endQuery(msg.Session, nil)
+
+		// Changes made in an open transaction are only committed with it.
+		status := fmt.Sprintf("%d statements committed", msg.Statements)
+		if s, ok := a.sessionManager.Get(msg.Session); ok && s.Transaction().Open {
+			status = fmt.Sprintf("%d statements applied in the open transaction", msg.Statements)
+		}
+		cmds = append(cmds, message.NewStatusUpdateCmd("APPLIED", status))
+
+	case message.FetchRowsMsg:
+		s, ok := a.sessionManager.Get(msg.Session)
+		if !ok {
+			break
+		}
+
+		ctx, result, ok := s.BeginFetch()
+		if !ok {
+			// Another query may have closed the result set, so the results
+			// stop waiting for more rows.
+			if !s.Running() {
+				cmds = append(cmds, func() tea.Msg { return message.RowsFetchedMsg{Session: s.Name} })
+			}
+			break
+		}
+
+		cmds = append(cmds, a.fetchRowsCmd(ctx, s.Name, result))
+
+	case message.QueryExecutedMsg:
+		a.endQuery(msg.Session, msg.Result)
+
+		// The next statement of a script runs once the previous one is done.
+		if len(msg.Remaining) > 0 {
+			cmds = append(cmds, func() tea.Msg {
+				return message.ExecuteQueryMsg{
+					Session:   msg.Session,
+					Query:     msg.Remaining[0].SQL,
+					Args:      msg.Remaining[0].Args,
+					Remaining: msg.Remaining[1:],
+					Target:    message.ResultsAppend,
+				}
+			})
+		}
+
+	case message.RowsFetchedMsg:
+		a.endQuery(msg.Session, msg.Result)
+
+	case message.QueryCancelledMsg:
+		a.endQuery(msg.Session, nil)
+		cmds = append(cmds, message.NewStatusUpdateCmd("CANCELLED", "Query cancelled"))
+
+	case message.ErrorMsg:
+		slog.Error("App.Update.ErrorMsg", "session", msg.Session, "error", msg.Err)
+		if msg.Session != "" {
+			a.endQuery(msg.Session, nil)
+		}
 	}
 
+	cmds = append(cmds, a.resumeWaiting())
+
 	screenModel, cmd := a.screenManager.Update(msg)
 	if sm, ok := screenModel.(*screenmanager.Screen); ok {
 		a.screenManager = sm
@@ -103,3 +445,305 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (a *App) View() string {
 	return a.screenManager.View()
 }
+
+// quit closes every session, which rolls back their open transactions, and
+// quits.
+func (a *App) quit() tea.Cmd {
+	err := a.sessionManager.CloseAll()
+	if err != nil {
+		slog.Error("App.quit", "error", err)
+	}
+
+	return tea.Quit
+}
+
+// nextSession activates the session after the active one.
+func (a *App) nextSession() tea.Cmd {
+	if a.sessionManager.Next() == nil {
+		return nil
+	}
+
+	return a.sessionsChangedCmd()
+}
+
+// beginStatement opens a transaction for the statement about to run if the
+// session runs statements in explicit transactions. The query that has just
+// been begun is ended if that fails.
+func (a *App) beginStatement(s *session.Session) error {
+	err := s.BeginStatement(context.Background())
+	if err != nil {
+		s.EndQuery(nil)
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	return nil
+}
+
+// sessionErrorCmd reports an error that ended the named session's query.
+func sessionErrorCmd(name string, err error) tea.Cmd {
+	return func() tea.Msg {
+		return message.ErrorMsg{Session: name, Err: err}
+	}
+}
+
+func (a *App) transactionChangedCmd(s *session.Session) tea.Cmd {
+	tx := s.Transaction()
+	return a.messageManager.NewTransactionChangedCmd(s.Name, tx.Manual, tx.Open, tx.Statements)
+}
+
+func (a *App) sessionsChangedCmd() tea.Cmd {
+	return a.messageManager.NewSessionsChangedCmd(a.sessionManager.ActiveName(), a.sessionManager.Names())
+}
+
+// runQueryCmd runs query with args on the session's database in the
+// background and fetches the first batch of rows.
+func (a *App) runQueryCmd(ctx context.Context, s *session.Session, query string, args []any, remaining []database.Statement) tea.Cmd {
+	db := s.Database
+	name := s.Name
+	settings := a.configService.QuerySettings()
+
+	return func() tea.Msg {
+		start := time.Now()
+		result, err := db.Run(ctx, query, args...)
+		if err == nil {
+			result.SetMaxRows(settings.MaxRows)
+			err = result.Fetch(settings.FetchSize)
+		}
+		duration := time.Since(start)
+
+		entry := history.Entry{
+			Connection: name,
+			Query:      query,
+			Time:       start,
+			Duration:   duration,
+		}
+
+		switch {
+		case ctx.Err() != nil:
+			if result != nil {
+				_ = result.Close()
+			}
+			entry.Error = "cancelled"
+			a.record(entry)
+			return message.QueryCancelledMsg{Session: name}
+		case err != nil:
+			// Fetching can fail after the query has run, with rows still
+			// holding the connection.
+			if result != nil {
+				_ = result.Close()
+			}
+			entry.Error = err.Error()
+			a.record(entry)
+			return message.ErrorMsg{Session: name, Err: err}
+		}
+
+		entry.Rows, entry.More = int64(len(result.Rows)), result.HasMore
+		if result.RowsAffected >= 0 {
+			entry.Rows = result.RowsAffected
+		}
+		a.record(entry)
+
+		return message.QueryExecutedMsg{
+			Session:   name,
+			Result:    result,
+			Duration:  duration,
+			Remaining: remaining,
+		}
+	}
+}
+
+// record adds a query that was run to the history. Failing to record it does
+// not fail the query.
+func (a *App) record(entry history.Entry) {
+	err := a.history.Add(entry)
+	if err != nil {
+		slog.Error("App.record", "error", err)
+	}
+}
+
+// estimateDangersCmd estimates the rows each dangerous statement of query
+// affects in the background. Statements without a WHERE clause affect every
+// row of their table, so it is the table's rows that are estimated.
+func (a *App) estimateDangersCmd(ctx context.Context, s *session.Session, query message.ExecuteQueryMsg, dangers []database.Danger) tea.Cmd {
+	db := s.Database
+	dialect := db.Dialect()
+	name := s.Name
+
+	return func() tea.Msg {
+		descriptions := make([]string, len(dangers))
+		for i, d := range dangers {
+			descriptions[i] = d.String()
+			if d.Table == "" {
+				continue
+			}
+
+			rows, err := db.EstimateRows(ctx, "SELECT * FROM "+dialect.QuoteTable(d.Schema, d.Table))
+			if err != nil {
+				slog.Debug("App.estimateDangersCmd", "schema", d.Schema, "table", d.Table, "error", err)
+				descriptions[i] += " (row count unknown)"
+				continue
+			}
+
+			descriptions[i] += fmt.Sprintf(" (~%d rows)", rows)
+		}
+
+		if ctx.Err() != nil {
+			return message.QueryCancelledMsg{Session: name}
+		}
+
+		return message.DangerousQueryMsg{
+			Session: name,
+			Query:   query,
+			Dangers: descriptions,
+		}
+	}
+}
+
+// loadCatalogCmd loads the session's catalog in the background.
+func (a *App) loadCatalogCmd(ctx context.Context, s *session.Session) tea.Cmd {
+	db := s.Database
+	name := s.Name
+
+	return func() tea.Msg {
+		catalog, err := db.Catalog(ctx)
+
+		return message.CatalogLoadedMsg{
+			Session: name,
+			Catalog: catalog,
+			Err:     err,
+		}
+	}
+}
+
+// loadObjectsCmd loads the objects inside the object at path in the
+// background.
+func (a *App) loadObjectsCmd(ctx context.Context, s *session.Session, path []database.Object) tea.Cmd {
+	db := s.Database
+	name := s.Name
+
+	return func() tea.Msg {
+		objects, err := db.Objects(ctx, path)
+
+		return message.ObjectsLoadedMsg{
+			Session: name,
+			Path:    path,
+			Objects: objects,
+			Err:     err,
+		}
+	}
+}
+
+// loadStructureCmd describes a table in the background.
+func (a *App) loadStructureCmd(ctx context.Context, s *session.Session, schema, table string) tea.Cmd {
+	db := s.Database
+	name := s.Name
+
+	return func() tea.Msg {
+		structure, err := db.Structure(ctx, schema, table)
+
+		return message.StructureLoadedMsg{
+			Session:   name,
+			Schema:    schema,
+			Table:     table,
+			Structure: structure,
+			Err:       err,
+		}
+	}
+}
+
+// applyChangesCmd executes statements in a single transaction in the
+// background.
+func (a *App) applyChangesCmd(ctx context.Context, s *session.Session, statements []database.Statement) tea.Cmd {
+	db := s.Database
+	name := s.Name
+
+	return func() tea.Msg {
+		err := db.ExecTx(ctx, statements)
+
+		switch {
+		case ctx.Err() != nil:
+			return message.QueryCancelledMsg{Session: name}
+		case err != nil:
+			return message.ErrorMsg{Session: name, Err: err}
+		}
+
+		return message.ChangesAppliedMsg{
+			Session:    name,
+			Statements: len(statements),
+		}
+	}
+}
+
+// fetchRowsCmd fetches the next batch of rows of an open result set in the
+// background.
+func (a *App) fetchRowsCmd(ctx context.Context, name string, result *database.QueryResult) tea.Cmd {
+	settings := a.configService.QuerySettings()
+
+	return func() tea.Msg {
+		err := result.Fetch(settings.FetchSize)
+
+		switch {
+		case ctx.Err() != nil:
+			return message.QueryCancelledMsg{Session: name}
+		case err != nil:
+			return message.ErrorMsg{Session: name, Err: err}
+		}
+
+		return message.RowsFetchedMsg{
+			Session: name,
+			Result:  result,
+		}
+	}
+}
+
+// wait holds msg until the named session's running query has finished.
+func (a *App) wait(name string, msg tea.Msg) {
+	slog.Debug("App.wait", "session", name, "msg", msg)
+	a.waiting[name] = append(a.waiting[name], msg)
+}
+
+// waitLoad makes a metadata load wait for s to be idle. A load that waits
+// for the open result set says so, as it may wait until another query runs.
+func (a *App) waitLoad(s *session.Session, msg tea.Msg) tea.Cmd {
+	a.wait(s.Name, msg)
+
+	if s.Running() {
+		return nil
+	}
+
+	return message.NewStatusUpdateCmd("WAITING", fmt.Sprintf("Loading from %s once the open result set is fetched or closed", s.Name))
+}
+
+// resumeWaiting sends the first waiting request of each session that is
+// idle. A request that finds its session busy again waits once more.
+func (a *App) resumeWaiting() tea.Cmd {
+	var cmds []tea.Cmd
+	for name, msgs := range a.waiting {
+		s, ok := a.sessionManager.Get(name)
+		if !ok {
+			delete(a.waiting, name)
+			continue
+		}
+		if !s.Idle() {
+			continue
+		}
+
+		if len(msgs) == 1 {
+			delete(a.waiting, name)
+		} else {
+			a.waiting[name] = msgs[1:]
+		}
+
+		msg := msgs[0]
+		cmds = append(cmds, func() tea.Msg { return msg })
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// endQuery marks the named session's running query or fetch as finished.
+func (a *App) endQuery(name string, result *database.QueryResult) {
+	if s, ok := a.sessionManager.Get(name); ok {
+		s.EndQuery(result)
+	}
+}
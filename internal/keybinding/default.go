@@ -7,17 +7,62 @@ type Keymap struct {
 	// Global keybindings
 	Quit          key.Binding
 	Cancel        key.Binding
+	Confirm       key.Binding
 	Help          key.Binding
 	NavigateUp    key.Binding
 	NavigateDown  key.Binding
 	NavigateLeft  key.Binding
 	NavigateRight key.Binding
+	NextSession   key.Binding
 
 	// Query keybindings
-	ExecuteQuery key.Binding
+	ExecuteQuery     key.Binding
+	ExecuteSelection key.Binding
+	ExecuteScript    key.Binding
+	MarkSelection    key.Binding
+	CancelQuery      key.Binding
+	ShowHistory      key.Binding
+	ShowSnippets     key.Binding
+
+	// Completion keybindings
+	AcceptCompletion   key.Binding
+	NextCompletion     key.Binding
+	PreviousCompletion key.Binding
+	ReloadCompletions  key.Binding
+
+	// Transaction keybindings
+	ToggleTransactions key.Binding
+	Commit             key.Binding
+	Rollback           key.Binding
+
+	// Result keybindings
+	ViewRow     key.Binding
+	PreviousRow key.Binding
+	NextRow     key.Binding
+	NextTab     key.Binding
+	PreviousTab key.Binding
+	PinTab      key.Binding
+
+	// Editing keybindings
+	NextColumn     key.Binding
+	PreviousColumn key.Binding
+	EditCell       key.Binding
+	SetNull        key.Binding
+	InsertRow      key.Binding
+	DeleteRow      key.Binding
+	RevertCell     key.Binding
+	ReviewChanges  key.Binding
+
+	// Schema browser keybindings
+	ExpandObject   key.Binding
+	CollapseObject key.Binding
+	FilterSchemas  key.Binding
+	ReloadObjects  key.Binding
+	ShowStructure  key.Binding
 
 	// Connection keybindings
-	AddConnection key.Binding
+	AddConnection   key.Binding
+	CloseConnection key.Binding
 }
 
 func NewKeymap() *Keymap {
@@ -30,6 +75,10 @@ func NewKeymap() *Keymap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "Cancel"),
 		),
+		Confirm: key.NewBinding(
+			key.WithKeys("enter", "y"),
+			key.WithHelp("enter/y", "Confirm"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "Help"),
@@ -50,14 +99,152 @@ func NewKeymap() *Keymap {
 			key.WithKeys("ctrl+l"),
 			key.WithHelp("ctrl+l", "Navigate right"),
 		),
+		// Keys handled before the focused screen sees them are kept off the
+		// editor's own keys, e.g. ctrl+t, which transposes characters.
+		NextSession: key.NewBinding(
+			key.WithKeys("alt+n"),
+			key.WithHelp("alt+n", "Next session"),
+		),
 		ExecuteQuery: key.NewBinding(
 			key.WithKeys("ctrl+e"),
-			key.WithHelp("ctrl+e", "Execute query"),
+			key.WithHelp("ctrl+e", "Execute statement under cursor"),
+		),
+		ExecuteSelection: key.NewBinding(
+			key.WithKeys("ctrl+g"),
+			key.WithHelp("ctrl+g", "Execute selection"),
+		),
+		ExecuteScript: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "Execute all statements"),
+		),
+		MarkSelection: key.NewBinding(
+			key.WithKeys("ctrl+@"),
+			key.WithHelp("ctrl+space", "Start or clear selection"),
+		),
+		CancelQuery: key.NewBinding(
+			key.WithKeys("alt+x"),
+			key.WithHelp("alt+x", "Cancel query"),
+		),
+		ShowHistory: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("ctrl+o", "Query history"),
+		),
+		ShowSnippets: key.NewBinding(
+			key.WithKeys("alt+s"),
+			key.WithHelp("alt+s", "Snippets"),
+		),
+		AcceptCompletion: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "Complete name"),
+		),
+		NextCompletion: key.NewBinding(
+			key.WithKeys("down"),
+			key.WithHelp("down", "Next completion"),
+		),
+		PreviousCompletion: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("up", "Previous completion"),
+		),
+		ReloadCompletions: key.NewBinding(
+			key.WithKeys("f5"),
+			key.WithHelp("f5", "Reload completions"),
+		),
+		ToggleTransactions: key.NewBinding(
+			key.WithKeys("alt+t"),
+			key.WithHelp("alt+t", "Toggle manual transactions"),
+		),
+		Commit: key.NewBinding(
+			key.WithKeys("alt+c"),
+			key.WithHelp("alt+c", "Commit transaction"),
+		),
+		Rollback: key.NewBinding(
+			key.WithKeys("alt+r"),
+			key.WithHelp("alt+r", "Roll back transaction"),
+		),
+		ViewRow: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "View row"),
+		),
+		PreviousRow: key.NewBinding(
+			key.WithKeys("["),
+			key.WithHelp("[", "Previous row"),
+		),
+		NextRow: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "Next row"),
+		),
+		NextTab: key.NewBinding(
+			key.WithKeys("}"),
+			key.WithHelp("}", "Next result tab"),
+		),
+		PreviousTab: key.NewBinding(
+			key.WithKeys("{"),
+			key.WithHelp("{", "Previous result tab"),
+		),
+		PinTab: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "Pin or unpin result tab"),
+		),
+		NextColumn: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "Next column"),
+		),
+		PreviousColumn: key.NewBinding(
+			key.WithKeys("shift+tab"),
+			key.WithHelp("shift+tab", "Previous column"),
+		),
+		EditCell: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "Edit cell"),
+		),
+		SetNull: key.NewBinding(
+			key.WithKeys("ctrl+n"),
+			key.WithHelp("ctrl+n", "Set NULL"),
+		),
+		InsertRow: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "Insert row"),
+		),
+		DeleteRow: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "Mark row for deletion"),
+		),
+		RevertCell: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "Revert cell"),
+		),
+		ReviewChanges: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "Review and apply changes"),
+		),
+		ExpandObject: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "Expand object"),
+		),
+		CollapseObject: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "Collapse object"),
+		),
+		FilterSchemas: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "Filter schemas"),
+		),
+		ReloadObjects: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "Reload objects"),
+		),
+		ShowStructure: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "Table structure"),
 		),
 		AddConnection: key.NewBinding(
 			key.WithKeys("ctrl+n"),
 			key.WithHelp("ctrl+n", "Add connection"),
 		),
+		CloseConnection: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "Close connection"),
+		),
 	}
 
 	// apply user defined keybindings here
@@ -69,12 +256,47 @@ func NewKeymap() *Keymap {
 func (k Keymap) Bindings() []key.Binding {
 	return []key.Binding{
 		k.Quit,
+		k.Confirm,
 		k.Help,
 		k.NavigateUp,
 		k.NavigateDown,
 		k.NavigateLeft,
 		k.NavigateRight,
+		k.NextSession,
 		k.ExecuteQuery,
+		k.ExecuteSelection,
+		k.ExecuteScript,
+		k.MarkSelection,
+		k.CancelQuery,
+		k.ShowHistory,
+		k.ShowSnippets,
+		k.AcceptCompletion,
+		k.NextCompletion,
+		k.PreviousCompletion,
+		k.ReloadCompletions,
+		k.ToggleTransactions,
+		k.Commit,
+		k.Rollback,
+		k.ViewRow,
+		k.PreviousRow,
+		k.NextRow,
+		k.NextTab,
+		k.PreviousTab,
+		k.PinTab,
+		k.NextColumn,
+		k.PreviousColumn,
+		k.EditCell,
+		k.SetNull,
+		k.InsertRow,
+		k.DeleteRow,
+		k.RevertCell,
+		k.ReviewChanges,
+		k.ExpandObject,
+		k.CollapseObject,
+		k.FilterSchemas,
+		k.ReloadObjects,
+		k.ShowStructure,
 		k.AddConnection,
+		k.CloseConnection,
 	}
 }
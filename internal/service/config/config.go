@@ -6,8 +6,25 @@ import (
 	"os"
 
 	"github.com/BurntSushi/toml"
+	"github.com/spf13/viper"
 )
 
+const (
+	// DefaultFetchSize is the number of rows fetched per batch.
+	DefaultFetchSize = 500
+	// DefaultMaxRows is the most rows fetched for a single query.
+	DefaultMaxRows = 10000
+)
+
+// QuerySettings control how query results are fetched. They are read from
+// the "query" section of the lazydb config file.
+type QuerySettings struct {
+	// FetchSize is the number of rows fetched at a time.
+	FetchSize int
+	// MaxRows caps the total rows fetched for a query. Zero disables the cap.
+	MaxRows int
+}
+
 type ConnectionConfig struct {
 	Type     string `toml:"type"`
 	Host     string `toml:"host"`
@@ -15,6 +32,16 @@ type ConnectionConfig struct {
 	Database string `toml:"database"`
 	User     string `toml:"user"`
 	Password string `toml:"password"`
+	// Path is the database file for file-backed drivers such as SQLite.
+	Path string `toml:"path,omitempty"`
+	// ReadOnly opens sessions that cannot change the database, and refuses
+	// statements that would.
+	ReadOnly bool `toml:"read_only,omitempty"`
+	// Label and Color mark the connection's sessions, e.g. "production" in
+	// red, so they cannot be mistaken for another connection's. Color is a
+	// hex colour or an ANSI colour number.
+	Label string `toml:"label,omitempty"`
+	Color string `toml:"color,omitempty"`
 }
 
 type ConnectionsConfig struct {
@@ -33,6 +60,23 @@ func NewService() *Service {
 	}
 }
 
+func (s *Service) QuerySettings() QuerySettings {
+	settings := QuerySettings{
+		FetchSize: DefaultFetchSize,
+		MaxRows:   DefaultMaxRows,
+	}
+
+	if viper.IsSet("query.fetch_size") && viper.GetInt("query.fetch_size") > 0 {
+		settings.FetchSize = viper.GetInt("query.fetch_size")
+	}
+
+	if viper.IsSet("query.max_rows") {
+		settings.MaxRows = max(viper.GetInt("query.max_rows"), 0)
+	}
+
+	return settings
+}
+
 func (s *Service) GetConnection(name string) (*ConnectionConfig, error) {
 	slog.Debug("s.ConnectionsConfig", "Connections", s.ConnectionsConfig.Connections)
 	conn, ok := s.ConnectionsConfig.Connections[name]
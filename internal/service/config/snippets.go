@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Snippet is a saved query. Its :name parameters are asked for each time it
+// is run.
+type Snippet struct {
+	Name        string
+	Description string
+	Query       string
+	// Connection is the connection the snippet belongs to, or empty if it
+	// can be run on any connection.
+	Connection string
+}
+
+type SnippetConfig struct {
+	Description string `toml:"description"`
+	Query       string `toml:"query"`
+}
+
+type ConnectionSnippetsConfig struct {
+	Snippets map[string]SnippetConfig `toml:"snippets"`
+}
+
+// SnippetsConfig is the snippets file. Snippets under [snippets.<name>] can
+// be run on any connection, those under [connections.<connection>.snippets.<name>]
+// only on that connection.
+type SnippetsConfig struct {
+	Snippets    map[string]SnippetConfig            `toml:"snippets"`
+	Connections map[string]ConnectionSnippetsConfig `toml:"connections"`
+}
+
+// LoadSnippets reads the snippets that can be run on the named connection
+// from the snippets file at path: the connection's own, followed by the
+// global ones it does not replace. A missing file has no snippets.
+func (s *Service) LoadSnippets(path string, connection string) ([]Snippet, error) {
+	var snippets SnippetsConfig
+
+	meta, err := toml.DecodeFile(path, &snippets)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not decode file: %w", err)
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("could not decode file: unknown keys %v", undecoded)
+	}
+
+	var result []Snippet
+	own := snippets.Connections[connection].Snippets
+	for _, name := range slices.Sorted(maps.Keys(own)) {
+		result = append(result, Snippet{
+			Name:        name,
+			Description: own[name].Description,
+			Query:       own[name].Query,
+			Connection:  connection,
+		})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(snippets.Snippets)) {
+		if _, ok := own[name]; ok {
+			continue
+		}
+
+		result = append(result, Snippet{
+			Name:        name,
+			Description: snippets.Snippets[name].Description,
+			Query:       snippets.Snippets[name].Query,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Catalog lists the objects of a database that queries can refer to, to
+// complete their names as they are typed.
+type Catalog struct {
+	// Schemas are the schemas of the database, or the databases on a MySQL
+	// server.
+	Schemas []string
+	// Tables are the tables and views with the names of their columns.
+	Tables    []CatalogTable
+	Functions []string
+}
+
+// CatalogTable is a table or view of a Catalog.
+type CatalogTable struct {
+	Schema  string
+	Name    string
+	Columns []string
+}
+
+// addColumn adds a column to the catalog. Columns must be added table by
+// table.
+func (c *Catalog) addColumn(schema, table, column string) {
+	if n := len(c.Tables); n == 0 || c.Tables[n-1].Schema != schema || c.Tables[n-1].Name != table {
+		c.Tables = append(c.Tables, CatalogTable{Schema: schema, Name: table})
+	}
+
+	last := &c.Tables[len(c.Tables)-1]
+	last.Columns = append(last.Columns, column)
+}
+
+// catalogQueries are the queries that list the objects of a Catalog.
+type catalogQueries struct {
+	// schemas returns the name of each schema.
+	schemas string
+	// columns returns the schema, table and name of each column, ordered by
+	// table.
+	columns string
+	// functions returns the name of each function.
+	functions string
+}
+
+// scanRowsFunc runs query with args bound to its placeholders and calls fn
+// with the scan function of each row.
+type scanRowsFunc func(ctx context.Context, query string, args []any, fn func(scan func(...any) error) error) error
+
+// loadCatalog lists the objects of a database with queries.
+func loadCatalog(ctx context.Context, queries catalogQueries, scanRows scanRowsFunc) (*Catalog, error) {
+	var catalog Catalog
+
+	err := scanRows(ctx, queries.schemas, nil, func(scan func(...any) error) error {
+		var schema string
+		if err := scan(&schema); err != nil {
+			return err
+		}
+
+		catalog.Schemas = append(catalog.Schemas, schema)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get schemas: %w", err)
+	}
+
+	err = scanRows(ctx, queries.columns, nil, func(scan func(...any) error) error {
+		var schema, table, column string
+		if err := scan(&schema, &table, &column); err != nil {
+			return err
+		}
+
+		catalog.addColumn(schema, table, column)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get columns: %w", err)
+	}
+
+	err = scanRows(ctx, queries.functions, nil, func(scan func(...any) error) error {
+		var function string
+		if err := scan(&function); err != nil {
+			return err
+		}
+
+		catalog.Functions = append(catalog.Functions, strings.ToLower(function))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get functions: %w", err)
+	}
+
+	return &catalog, nil
+}
+
+// scanRows implements scanRowsFunc, running query in the open transaction if
+// there is one.
+func (c *sqlConn) scanRows(ctx context.Context, query string, args []any, fn func(scan func(...any) error) error) error {
+	rows, err := c.queryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err = fn(rows.Scan)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,287 @@
+package database
+
+import (
+	"slices"
+	"strings"
+)
+
+// StatementKind classifies what a statement does. Kinds are ordered by how
+// much they change, so the kind of several statements is the largest one.
+type StatementKind int
+
+const (
+	// StatementRead reads data or only changes the state of the session,
+	// such as SET or BEGIN.
+	StatementRead StatementKind = iota
+	// StatementWrite changes rows.
+	StatementWrite
+	// StatementDDL changes the schema or permissions.
+	StatementDDL
+)
+
+func (k StatementKind) String() string {
+	switch k {
+	case StatementWrite:
+		return "write"
+	case StatementDDL:
+		return "DDL"
+	}
+
+	return "read"
+}
+
+var (
+	readKeywords = []string{
+		"SELECT", "SHOW", "DESCRIBE", "DESC", "EXPLAIN", "VALUES", "TABLE", "WITH",
+		"SET", "RESET", "BEGIN", "START", "COMMIT", "END", "ROLLBACK", "SAVEPOINT", "RELEASE",
+		"USE", "FETCH", "MOVE", "CLOSE", "LISTEN", "UNLISTEN", "DISCARD", "PRAGMA",
+	}
+	ddlKeywords = []string{
+		"CREATE", "ALTER", "DROP", "TRUNCATE", "RENAME", "GRANT", "REVOKE", "COMMENT",
+		"REINDEX", "CLUSTER", "VACUUM", "ANALYZE", "ANALYSE", "REFRESH", "SECURITY",
+		"REASSIGN", "OPTIMIZE", "ATTACH", "DETACH",
+	}
+	writeKeywords = []string{"INSERT", "UPDATE", "DELETE", "MERGE", "REPLACE", "UPSERT"}
+	// inspectPragmas are the SQLite pragmas that take an argument but only
+	// describe the database.
+	inspectPragmas = []string{
+		"table_info", "table_xinfo", "table_list", "index_list", "index_info", "index_xinfo",
+		"foreign_key_list", "foreign_key_check", "integrity_check", "quick_check",
+	}
+	uncountedBlocks = []string{"IF", "LOOP", "WHILE", "REPEAT"}
+)
+
+// Span is the location of a statement in a script, as byte offsets.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Split returns the locations of the statements in script, which are
+// separated by semicolons. A statement's span starts at its first token and
+// ends after its last, so comments before it and the semicolon after it are
+// left out.
+func (d Dialect) Split(script string) []Span {
+	statements := splitTokens(d.lex(script))
+
+	spans := make([]Span, len(statements))
+	for i, stmt := range statements {
+		last := stmt[len(stmt)-1]
+		spans[i] = Span{Start: stmt[0].pos, End: last.pos + len(last.text)}
+	}
+
+	return spans
+}
+
+// returnsRows reports whether query should be run for the rows it returns,
+// or executed for the number of rows it changes. Writes only return rows with
+// a RETURNING clause, and procedures may return rows whatever they do.
+func (d Dialect) returnsRows(query string) bool {
+	tokens := d.lex(query)
+	if len(tokens) > 0 && tokens[0].is("CALL") || d.Classify(query) == StatementRead {
+		return true
+	}
+
+	for _, t := range tokens {
+		if t.is("RETURNING") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// command returns the keyword query starts with, e.g. "UPDATE".
+func (d Dialect) command(query string) string {
+	tokens := d.lex(query)
+	if len(tokens) == 0 || tokens[0].kind != tokenWord {
+		return ""
+	}
+
+	return strings.ToUpper(tokens[0].text)
+}
+
+// Classify returns what the statements in query do. Statements that are not
+// recognised are classified as writes, so that they are never mistaken for
+// being safe to run.
+func (d Dialect) Classify(query string) StatementKind {
+	kind := StatementRead
+	for _, stmt := range splitTokens(d.lex(query)) {
+		kind = max(kind, classifyTokens(stmt))
+	}
+
+	return kind
+}
+
+// ChangesSettings reports whether a statement in query changes the settings
+// of the session. Classify counts these as reads, as they change no rows, but
+// read-only connections must refuse them: they could turn off the setting
+// that keeps the connection read-only, e.g. with PRAGMA query_only(0) or SET
+// SESSION TRANSACTION READ WRITE.
+func (d Dialect) ChangesSettings(query string) bool {
+	return slices.ContainsFunc(splitTokens(d.lex(query)), changesSettings)
+}
+
+func changesSettings(tokens []token) bool {
+	for i, t := range tokens {
+		if t.is("set_config") && i+1 < len(tokens) && tokens[i+1].text == "(" {
+			return true
+		}
+	}
+
+	switch {
+	case tokens[0].is("SET"), tokens[0].is("RESET"), tokens[0].is("DISCARD"):
+		return true
+
+	case tokens[0].is("BEGIN"), tokens[0].is("START"):
+		// A transaction can be opened READ WRITE whatever the session's
+		// default is.
+		return slices.ContainsFunc(tokens, func(t token) bool { return t.is("WRITE") })
+
+	case tokens[0].is("PRAGMA"):
+		// Pragmas are changed by assigning them, or by passing the new
+		// value in parentheses.
+		for i, t := range tokens {
+			switch t.text {
+			case "=":
+				return true
+			case "(":
+				return !containsKeyword(inspectPragmas, tokens[i-1])
+			}
+		}
+	}
+
+	return false
+}
+
+// splitTokens splits tokens into the statements separated by semicolons.
+// Semicolons in the BEGIN ... END body of a CREATE statement, such as a
+// trigger, do not end it.
+func splitTokens(tokens []token) [][]token {
+	var statements [][]token
+
+	start, blocks := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.kind == tokenPunct && t.text == ";" && blocks == 0:
+			if i > start {
+				statements = append(statements, tokens[start:i])
+			}
+			start = i + 1
+
+		case !tokens[start].is("CREATE"):
+
+		case t.is("BEGIN"), t.is("CASE"):
+			blocks++
+
+		case t.is("END") && blocks > 0:
+			// IF, LOOP, WHILE and REPEAT blocks are not counted, so neither
+			// are their ends.
+			if i+1 < len(tokens) && containsKeyword(uncountedBlocks, tokens[i+1]) {
+				break
+			}
+			blocks--
+		}
+	}
+
+	if start < len(tokens) {
+		statements = append(statements, tokens[start:])
+	}
+
+	return statements
+}
+
+func classifyTokens(tokens []token) StatementKind {
+	// Skip the parentheses of a parenthesised query.
+	for len(tokens) > 0 && tokens[0].kind == tokenPunct && tokens[0].text == "(" {
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) == 0 || tokens[0].kind != tokenWord {
+		return StatementWrite
+	}
+
+	first := strings.ToUpper(tokens[0].text)
+	switch first {
+	case "EXPLAIN":
+		// EXPLAIN ANALYZE runs the statement it explains.
+		for i, t := range tokens[1:] {
+			if t.is("ANALYZE") || t.is("ANALYSE") {
+				return classifyTokens(explained(tokens[i+2:]))
+			}
+		}
+		return StatementRead
+
+	case "WITH":
+		// Common table expressions may change rows, as may the statement
+		// that uses them.
+		for _, t := range tokens {
+			if containsKeyword(writeKeywords, t) {
+				return StatementWrite
+			}
+		}
+		return StatementRead
+
+	case "SELECT":
+		// SELECT INTO creates a table in Postgres and writes a file in MySQL.
+		for _, t := range tokens {
+			if t.is("INTO") {
+				return StatementWrite
+			}
+		}
+		return StatementRead
+
+	case "PRAGMA":
+		// Pragmas that are assigned change the database or the connection.
+		for _, t := range tokens {
+			if t.kind == tokenPunct && t.text == "=" {
+				return StatementWrite
+			}
+		}
+		return StatementRead
+
+	case "COPY":
+		// COPY reads a table out TO somewhere, or writes rows into it FROM
+		// somewhere.
+		for _, t := range tokens {
+			switch {
+			case t.depth == 0 && t.is("TO"):
+				return StatementRead
+			case t.depth == 0 && t.is("FROM"):
+				return StatementWrite
+			}
+		}
+		return StatementWrite
+	}
+
+	switch {
+	case containsKeyword(readKeywords, tokens[0]):
+		return StatementRead
+	case containsKeyword(ddlKeywords, tokens[0]):
+		return StatementDDL
+	}
+
+	return StatementWrite
+}
+
+// explained returns the statement following the options of an EXPLAIN
+// ANALYZE statement.
+func explained(tokens []token) []token {
+	for i, t := range tokens {
+		if t.kind == tokenWord && (containsKeyword(readKeywords, t) || containsKeyword(writeKeywords, t) || containsKeyword(ddlKeywords, t)) {
+			return tokens[i:]
+		}
+	}
+
+	return nil
+}
+
+func containsKeyword(keywords []string, t token) bool {
+	for _, keyword := range keywords {
+		if t.is(keyword) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChangesSettings(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    bool
+	}{
+		{DialectPostgres, "SET default_transaction_read_only = off", true},
+		{DialectPostgres, "set transaction_read_only = off", true},
+		{DialectPostgres, "RESET ALL", true},
+		{DialectPostgres, "DISCARD ALL", true},
+		{DialectPostgres, "BEGIN READ WRITE", true},
+		{DialectPostgres, "SELECT set_config('default_transaction_read_only', 'off', false)", true},
+		{DialectPostgres, "SELECT 1; SET search_path = other", true},
+		{DialectMySQL, "SET SESSION TRANSACTION READ WRITE", true},
+		{DialectMySQL, "START TRANSACTION READ WRITE", true},
+		{DialectMySQL, "SET @n = 1", true},
+		{DialectSQLite, "PRAGMA query_only(0)", true},
+		{DialectSQLite, "PRAGMA query_only = 0", true},
+		{DialectSQLite, "PRAGMA main.foreign_keys = ON", true},
+
+		{DialectPostgres, "SELECT * FROM settings WHERE name = 'SET x = 1'", false},
+		{DialectPostgres, "SHOW default_transaction_read_only", false},
+		{DialectPostgres, "BEGIN", false},
+		{DialectPostgres, "UPDATE t SET a = 1", false},
+		{DialectMySQL, "START TRANSACTION READ ONLY", false},
+		{DialectSQLite, "PRAGMA query_only", false},
+		{DialectSQLite, "PRAGMA table_info(people)", false},
+		{DialectSQLite, "PRAGMA main.index_list('people')", false},
+		{DialectSQLite, "-- SET x = 1\nSELECT 1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := tt.dialect.ChangesSettings(tt.query); got != tt.want {
+				t.Errorf("ChangesSettings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    StatementKind
+	}{
+		{DialectPostgres, "SELECT * FROM t", StatementRead},
+		{DialectPostgres, "(SELECT 1) UNION (SELECT 2)", StatementRead},
+		{DialectPostgres, "with x as (select 1) select * from x", StatementRead},
+		{DialectPostgres, "EXPLAIN DELETE FROM t", StatementRead},
+		{DialectPostgres, "SHOW search_path", StatementRead},
+		{DialectPostgres, "BEGIN", StatementRead},
+		{DialectPostgres, "COPY t TO STDOUT", StatementRead},
+		{DialectPostgres, "SELECT 'DELETE FROM t'", StatementRead},
+		{DialectPostgres, "SELECT $$DROP TABLE t$$", StatementRead},
+		{DialectPostgres, "SELECT 1 /* DROP TABLE t */", StatementRead},
+		{DialectSQLite, "PRAGMA table_info(t)", StatementRead},
+		{DialectMySQL, "DESCRIBE t", StatementRead},
+
+		{DialectPostgres, "INSERT INTO t VALUES (1)", StatementWrite},
+		{DialectPostgres, "UPDATE t SET a = 1", StatementWrite},
+		{DialectPostgres, "WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", StatementWrite},
+		{DialectPostgres, "EXPLAIN ANALYZE DELETE FROM t", StatementWrite},
+		{DialectPostgres, "EXPLAIN (ANALYZE, BUFFERS) UPDATE t SET a = 1", StatementWrite},
+		{DialectPostgres, "SELECT * INTO u FROM t", StatementWrite},
+		{DialectPostgres, "COPY t FROM STDIN", StatementWrite},
+		{DialectPostgres, "CALL p()", StatementWrite},
+		{DialectPostgres, "DO $$ BEGIN END $$", StatementWrite},
+		{DialectSQLite, "PRAGMA foreign_keys = ON", StatementWrite},
+		{DialectMySQL, "REPLACE INTO t VALUES (1)", StatementWrite},
+
+		{DialectPostgres, "CREATE TABLE t (a int)", StatementDDL},
+		{DialectPostgres, "drop table t", StatementDDL},
+		{DialectPostgres, "TRUNCATE t", StatementDDL},
+		{DialectPostgres, "GRANT SELECT ON t TO u", StatementDDL},
+		{DialectPostgres, "EXPLAIN ANALYZE CREATE TABLE u AS SELECT 1", StatementDDL},
+		{DialectSQLite, "ATTACH 'x.db' AS x", StatementDDL},
+
+		// The kind of a script is the largest kind of its statements.
+		{DialectPostgres, "SELECT 1; UPDATE t SET a = 1; SELECT 2", StatementWrite},
+		{DialectPostgres, "INSERT INTO t VALUES (1); DROP TABLE u", StatementDDL},
+		{DialectPostgres, "", StatementRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := tt.dialect.Classify(tt.query); got != tt.want {
+				t.Errorf("Classify = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		script  string
+		want    []string
+	}{
+		{"statements", DialectPostgres, "SELECT 1; SELECT 2", []string{"SELECT 1", "SELECT 2"}},
+		{"comments and empty statements", DialectPostgres, "-- first\nSELECT 1 /* one */;;\n\n  SELECT 2;  -- last", []string{"SELECT 1", "SELECT 2"}},
+		{"semicolons in strings", DialectPostgres, "SELECT ';'; SELECT \"a;b\"", []string{"SELECT ';'", `SELECT "a;b"`}},
+		{"semicolons in comments", DialectPostgres, "SELECT 1 /* ; /* ; */ ; */; SELECT 2", []string{"SELECT 1", "SELECT 2"}},
+		{"dollar quoted bodies", DialectPostgres, "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql; SELECT f()", []string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql", "SELECT f()"}},
+		{"trigger bodies", DialectSQLite, "CREATE TRIGGER t AFTER INSERT ON a BEGIN UPDATE b SET n = CASE WHEN 1 THEN 2 END; DELETE FROM c; END; SELECT 1", []string{"CREATE TRIGGER t AFTER INSERT ON a BEGIN UPDATE b SET n = CASE WHEN 1 THEN 2 END; DELETE FROM c; END", "SELECT 1"}},
+		{"procedure bodies", DialectMySQL, "CREATE PROCEDURE p() BEGIN IF 1 THEN SELECT 1; END IF; END; CALL p()", []string{"CREATE PROCEDURE p() BEGIN IF 1 THEN SELECT 1; END IF; END", "CALL p()"}},
+		{"transactions are not blocks", DialectPostgres, "BEGIN; SELECT 1; END;", []string{"BEGIN", "SELECT 1", "END"}},
+		{"hash comments", DialectMySQL, "SELECT 1 # ;\n; SELECT 2", []string{"SELECT 1", "SELECT 2"}},
+		{"empty", DialectPostgres, " -- nothing\n", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, span := range tt.dialect.Split(tt.script) {
+				got = append(got, tt.script[span.Start:span.End])
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
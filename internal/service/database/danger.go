@@ -0,0 +1,143 @@
+package database
+
+import "strings"
+
+// Danger describes a statement that may destroy data, such as a DELETE
+// without a WHERE clause.
+type Danger struct {
+	// Reason says what the statement does, e.g. "DELETE without WHERE".
+	Reason string
+	// Schema and Table name the table the statement changes, as the
+	// database knows them. Table is empty if the statement does not change a
+	// single table, and Schema if the name is not qualified.
+	Schema string
+	Table  string
+}
+
+func (d Danger) String() string {
+	switch {
+	case d.Table == "":
+		return d.Reason
+	case d.Schema == "":
+		return d.Reason + " on " + d.Table
+	}
+
+	return d.Reason + " on " + d.Schema + "." + d.Table
+}
+
+// Dangers returns the statements in query that may destroy data: UPDATE and
+// DELETE without a WHERE clause, TRUNCATE, DROP and ALTER.
+func (d Dialect) Dangers(query string) []Danger {
+	var dangers []Danger
+	for _, stmt := range splitTokens(d.lex(query)) {
+		if danger, ok := d.statementDanger(stmt); ok {
+			dangers = append(dangers, danger)
+		}
+	}
+
+	return dangers
+}
+
+func (d Dialect) statementDanger(tokens []token) (Danger, bool) {
+	// The statement a WITH clause belongs to follows the common table
+	// expressions, which are in parentheses.
+	if len(tokens) > 0 && tokens[0].is("WITH") {
+		i := 1
+		for i < len(tokens) && (tokens[i].depth > 0 || !containsKeyword(writeKeywords, tokens[i]) && !tokens[i].is("SELECT")) {
+			i++
+		}
+		tokens = tokens[i:]
+	}
+
+	if len(tokens) == 0 {
+		return Danger{}, false
+	}
+
+	first := strings.ToUpper(tokens[0].text)
+	switch {
+	case first == "UPDATE" || first == "DELETE":
+		for _, t := range tokens {
+			if t.depth == 0 && t.is("WHERE") {
+				return Danger{}, false
+			}
+		}
+
+		danger := Danger{Reason: first + " without WHERE"}
+		danger.Schema, danger.Table = d.tableAfter(tokens[1:], "FROM", "ONLY", "LOW_PRIORITY", "QUICK", "IGNORE")
+
+		return danger, true
+
+	case first == "TRUNCATE":
+		danger := Danger{Reason: first}
+		danger.Schema, danger.Table = d.tableAfter(tokens[1:], "TABLE", "ONLY")
+
+		return danger, true
+
+	case first == "DROP" || first == "ALTER":
+		// Only tables have rows that are lost, but dropping or altering any
+		// object may break everything that uses it.
+		danger := Danger{Reason: first}
+		if len(tokens) > 1 {
+			danger.Reason += " " + strings.ToUpper(tokens[1].text)
+			if tokens[1].is("TABLE") {
+				danger.Schema, danger.Table = d.tableAfter(tokens[2:], "IF", "EXISTS", "ONLY")
+			}
+		}
+
+		return danger, true
+	}
+
+	return Danger{}, false
+}
+
+// tableAfter returns the schema and name of the table that tokens start
+// with, after skipping any of the keywords.
+func (d Dialect) tableAfter(tokens []token, keywords ...string) (string, string) {
+	for len(tokens) > 0 && containsKeyword(keywords, tokens[0]) {
+		tokens = tokens[1:]
+	}
+
+	// A name is a word or quoted identifier, possibly qualified with dots,
+	// and cannot end with a dot.
+	end := 0
+	for end < len(tokens) && isNamePart(tokens[end], end%2 == 0) {
+		end++
+	}
+	if end%2 == 0 {
+		end--
+	}
+	if end <= 0 {
+		return "", ""
+	}
+
+	name := d.identifierName(tokens[end-1].text)
+	if end == 1 {
+		return "", name
+	}
+
+	return d.identifierName(tokens[end-3].text), name
+}
+
+// identifierName returns the name an identifier refers to: a quoted one's
+// name, or an unquoted one as the database folds it. Postgres folds unquoted
+// names to lower case.
+func (d Dialect) identifierName(text string) string {
+	if text != "" && (text[0] == '"' || text[0] == '`') {
+		return unquoteIdentifier(text)
+	}
+	if d == DialectPostgres {
+		return strings.ToLower(text)
+	}
+
+	return text
+}
+
+// isNamePart reports whether t is an identifier, if identifier is set, or
+// the dot between the parts of a qualified name.
+func isNamePart(t token, identifier bool) bool {
+	if identifier {
+		return t.kind == tokenWord || t.kind == tokenQuoted && !strings.HasPrefix(t.text, "'")
+	}
+
+	return t.kind == tokenPunct && t.text == "."
+}
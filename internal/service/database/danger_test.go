@@ -0,0 +1,80 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDangers(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    []Danger
+	}{
+		{DialectPostgres, "DELETE FROM t", []Danger{{Reason: "DELETE without WHERE", Table: "t"}}},
+		{DialectPostgres, "delete from only public.t", []Danger{{Reason: "DELETE without WHERE", Schema: "public", Table: "t"}}},
+		{DialectPostgres, `UPDATE "My Table" SET a = 1`, []Danger{{Reason: "UPDATE without WHERE", Table: "My Table"}}},
+		{DialectPostgres, "UPDATE Public.People SET a = (SELECT b FROM u WHERE u.id = 1)", []Danger{{Reason: "UPDATE without WHERE", Schema: "public", Table: "people"}}},
+		{DialectMySQL, "DELETE LOW_PRIORITY QUICK FROM `db`.`Order`", []Danger{{Reason: "DELETE without WHERE", Schema: "db", Table: "Order"}}},
+		{DialectSQLite, "DELETE FROM People", []Danger{{Reason: "DELETE without WHERE", Table: "People"}}},
+		{DialectPostgres, "TRUNCATE TABLE t", []Danger{{Reason: "TRUNCATE", Table: "t"}}},
+		{DialectPostgres, "DROP TABLE IF EXISTS t", []Danger{{Reason: "DROP TABLE", Table: "t"}}},
+		{DialectPostgres, "DROP INDEX i", []Danger{{Reason: "DROP INDEX"}}},
+		{DialectPostgres, "ALTER TABLE ONLY t DROP COLUMN a", []Danger{{Reason: "ALTER TABLE", Table: "t"}}},
+		{DialectPostgres, "WITH x AS (SELECT 1) DELETE FROM t", []Danger{{Reason: "DELETE without WHERE", Table: "t"}}},
+		{DialectPostgres, "SELECT 1; DELETE FROM t; DROP VIEW v", []Danger{{Reason: "DELETE without WHERE", Table: "t"}, {Reason: "DROP VIEW"}}},
+
+		{DialectPostgres, "DELETE FROM t WHERE id = 1", nil},
+		{DialectPostgres, "UPDATE t SET a = 1 WHERE id IN (SELECT id FROM u)", nil},
+		{DialectPostgres, "WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d WHERE true", nil},
+		{DialectPostgres, "SELECT 'DROP TABLE t'", nil},
+		{DialectPostgres, "SELECT $$DELETE FROM t$$", nil},
+		{DialectPostgres, "-- DELETE FROM t\nSELECT 1", nil},
+		{DialectPostgres, "INSERT INTO t VALUES (1)", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := tt.dialect.Dangers(tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDangerString(t *testing.T) {
+	tests := []struct {
+		danger Danger
+		want   string
+	}{
+		{Danger{Reason: "DROP INDEX"}, "DROP INDEX"},
+		{Danger{Reason: "TRUNCATE", Table: "t"}, "TRUNCATE on t"},
+		{Danger{Reason: "DELETE without WHERE", Schema: "public", Table: "t"}, "DELETE without WHERE on public.t"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.danger.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestQuoteTable(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		schema  string
+		table   string
+		want    string
+	}{
+		{DialectPostgres, "", "order", `"order"`},
+		{DialectPostgres, "public", `My "Table"`, `"public"."My ""Table"""`},
+		{DialectMySQL, "db", "Order", "`db`.`Order`"},
+		{DialectSQLite, "", "select", `"select"`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.QuoteTable(tt.schema, tt.table); got != tt.want {
+			t.Errorf("QuoteTable(%q, %q) = %s, want %s", tt.schema, tt.table, got, tt.want)
+		}
+	}
+}
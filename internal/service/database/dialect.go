@@ -0,0 +1,194 @@
+package database
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Dialect is the flavour of SQL a database understands, which decides how
+// identifiers are quoted and how arguments are passed to statements.
+type Dialect int
+
+const (
+	DialectPostgres Dialect = iota
+	DialectMySQL
+	DialectSQLite
+)
+
+// Statement is a statement to execute with its arguments.
+type Statement struct {
+	SQL  string
+	Args []any
+	// Preview is the statement with its arguments written inline, for
+	// display only.
+	Preview string
+}
+
+// Assignment sets a column to a value.
+type Assignment struct {
+	Column string
+	Value  any
+}
+
+// QuoteIdentifier quotes a table or column name.
+func (d Dialect) QuoteIdentifier(name string) string {
+	if d == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Literal writes a value as an SQL literal.
+func (d Dialect) Literal(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case []byte:
+		if d == DialectPostgres {
+			return `'\x` + hex.EncodeToString(v) + `'`
+		}
+		return "X'" + hex.EncodeToString(v) + "'"
+	}
+
+	f := FormatValue(Column{}, v)
+	if f.Kind == KindNumber {
+		return f.Text
+	}
+
+	return "'" + strings.ReplaceAll(f.Text, "'", "''") + "'"
+}
+
+func (d Dialect) placeholder(n int) string {
+	if d == DialectPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+
+	return "?"
+}
+
+// QuoteTable quotes a table name, qualified with schema unless it is empty.
+func (d Dialect) QuoteTable(schema, table string) string {
+	if schema == "" {
+		return d.QuoteIdentifier(table)
+	}
+
+	return d.QuoteIdentifier(schema) + "." + d.QuoteIdentifier(table)
+}
+
+// tableName is the quoted, schema qualified name of source's table.
+func (d Dialect) tableName(source *TableSource) string {
+	return d.QuoteTable(source.Schema, source.Table)
+}
+
+// Update returns the statement that makes the assignments to the row of
+// source's table whose primary key columns hold key, in order.
+func (d Dialect) Update(source *TableSource, set []Assignment, key []any) Statement {
+	b := statementBuilder{dialect: d}
+
+	b.write("UPDATE " + d.tableName(source) + " SET ")
+	for i, a := range set {
+		if i > 0 {
+			b.write(", ")
+		}
+		b.write(d.QuoteIdentifier(a.Column) + " = ")
+		b.arg(a.Value)
+	}
+
+	b.where(source.PrimaryKey(), key)
+
+	return b.statement()
+}
+
+// Insert returns the statement that inserts a row into source's table with
+// the assigned values. Columns without a value get their default.
+func (d Dialect) Insert(source *TableSource, set []Assignment) Statement {
+	b := statementBuilder{dialect: d}
+
+	b.write("INSERT INTO " + d.tableName(source))
+
+	if len(set) == 0 {
+		if d == DialectMySQL {
+			b.write(" () VALUES ()")
+		} else {
+			b.write(" DEFAULT VALUES")
+		}
+
+		return b.statement()
+	}
+
+	b.write(" (")
+	for i, a := range set {
+		if i > 0 {
+			b.write(", ")
+		}
+		b.write(d.QuoteIdentifier(a.Column))
+	}
+
+	b.write(") VALUES (")
+	for i, a := range set {
+		if i > 0 {
+			b.write(", ")
+		}
+		b.arg(a.Value)
+	}
+	b.write(")")
+
+	return b.statement()
+}
+
+// Delete returns the statement that deletes the row of source's table whose
+// primary key columns hold key, in order.
+func (d Dialect) Delete(source *TableSource, key []any) Statement {
+	b := statementBuilder{dialect: d}
+
+	b.write("DELETE FROM " + d.tableName(source))
+	b.where(source.PrimaryKey(), key)
+
+	return b.statement()
+}
+
+// statementBuilder writes a statement and its preview side by side.
+type statementBuilder struct {
+	dialect Dialect
+	sql     strings.Builder
+	preview strings.Builder
+	args    []any
+}
+
+func (b *statementBuilder) write(s string) {
+	b.sql.WriteString(s)
+	b.preview.WriteString(s)
+}
+
+func (b *statementBuilder) arg(v any) {
+	b.args = append(b.args, v)
+	b.sql.WriteString(b.dialect.placeholder(len(b.args)))
+	b.preview.WriteString(b.dialect.Literal(v))
+}
+
+// where matches the row whose columns hold values.
+func (b *statementBuilder) where(columns []string, values []any) {
+	b.write(" WHERE ")
+	for i, name := range columns {
+		if i > 0 {
+			b.write(" AND ")
+		}
+		b.write(b.dialect.QuoteIdentifier(name) + " = ")
+		b.arg(values[i])
+	}
+}
+
+func (b *statementBuilder) statement() Statement {
+	return Statement{
+		SQL:     b.sql.String(),
+		Args:    b.args,
+		Preview: b.preview.String() + ";",
+	}
+}
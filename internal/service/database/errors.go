@@ -0,0 +1,94 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// QueryError is a driver error enriched with the details a database reported
+// about why a query failed.
+type QueryError struct {
+	Query   string
+	Message string
+	// Code is the SQLSTATE or vendor error code, if known.
+	Code   string
+	Detail string
+	Hint   string
+	// Position is the 1-based character offset into Query the error refers
+	// to, or 0 if unknown.
+	Position int
+
+	Err error
+}
+
+func (e *QueryError) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+
+	return e.Message + " (SQLSTATE " + e.Code + ")"
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Line returns the 0-based line and column of Position within Query.
+func (e *QueryError) Line() (line int, col int, ok bool) {
+	if e.Position <= 0 {
+		return 0, 0, false
+	}
+
+	runes := []rune(e.Query)
+	if e.Position > len(runes)+1 {
+		return 0, 0, false
+	}
+
+	for _, r := range runes[:e.Position-1] {
+		if r == '\n' {
+			line++
+			col = 0
+			continue
+		}
+		col++
+	}
+
+	return line, col, true
+}
+
+// newQueryError wraps err in a QueryError, pulling out the details of the
+// errors returned by the supported drivers.
+func newQueryError(query string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	qe := &QueryError{
+		Query:   query,
+		Message: err.Error(),
+		Err:     err,
+	}
+
+	var pgErr *pgconn.PgError
+	var myErr *mysql.MySQLError
+
+	switch {
+	case errors.As(err, &pgErr):
+		qe.Message = strings.TrimSpace(pgErr.Severity + ": " + pgErr.Message)
+		qe.Code = pgErr.Code
+		qe.Detail = pgErr.Detail
+		qe.Hint = pgErr.Hint
+		qe.Position = int(pgErr.Position)
+	case errors.As(err, &myErr):
+		qe.Message = myErr.Message
+		qe.Code = string(myErr.SQLState[:])
+		if qe.Code == "\x00\x00\x00\x00\x00" {
+			qe.Code = ""
+		}
+	}
+
+	return qe
+}
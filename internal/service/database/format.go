@@ -0,0 +1,279 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ValueKind classifies a formatted value so it can be displayed accordingly.
+type ValueKind int
+
+const (
+	KindText ValueKind = iota
+	KindNull
+	KindNumber
+	KindBool
+	KindTime
+	KindJSON
+	KindBinary
+)
+
+// FormattedValue is the display form of a value from a result set.
+type FormattedValue struct {
+	Text string
+	Kind ValueKind
+	// Size is the size in bytes of the raw value for binary values, and of
+	// Text otherwise.
+	Size int
+}
+
+// FormatValue renders a value read from a column of a result set. The
+// column's type name is used to tell apart values that share a Go type, such
+// as dates and timestamps or decimals returned as strings.
+func FormatValue(col Column, v any) FormattedValue {
+	f := formatValue(col, v)
+	if f.Kind != KindBinary {
+		f.Size = len(f.Text)
+	}
+
+	return f
+}
+
+func formatValue(col Column, v any) FormattedValue {
+	typeName := strings.ToLower(col.TypeName)
+
+	switch v := v.(type) {
+	case nil:
+		return FormattedValue{Text: "NULL", Kind: KindNull}
+	case bool:
+		return FormattedValue{Text: strconv.FormatBool(v), Kind: KindBool}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return FormattedValue{Text: fmt.Sprintf("%d", v), Kind: KindNumber}
+	case float32:
+		return FormattedValue{Text: strconv.FormatFloat(float64(v), 'f', -1, 32), Kind: KindNumber}
+	case float64:
+		return FormattedValue{Text: strconv.FormatFloat(v, 'f', -1, 64), Kind: KindNumber}
+	case *big.Int:
+		return FormattedValue{Text: v.String(), Kind: KindNumber}
+	case pgtype.Numeric:
+		return formatNumeric(v)
+	case string:
+		if isNumericType(typeName) {
+			return FormattedValue{Text: v, Kind: KindNumber}
+		}
+		if typeName == "json" || typeName == "jsonb" {
+			return FormattedValue{Text: v, Kind: KindJSON}
+		}
+		return FormattedValue{Text: v, Kind: KindText}
+	case []byte:
+		// Postgres writes bytea as \x followed by hex, other databases use 0x.
+		prefix := "0x"
+		if typeName == "bytea" {
+			prefix = `\x`
+		}
+		return FormattedValue{Text: prefix + hex.EncodeToString(v), Kind: KindBinary, Size: len(v)}
+	case [16]byte:
+		return FormattedValue{Text: uuid.UUID(v).String(), Kind: KindText}
+	case time.Time:
+		return FormattedValue{Text: formatTime(typeName, v), Kind: KindTime}
+	case pgtype.Time:
+		if !v.Valid {
+			return FormattedValue{Text: "NULL", Kind: KindNull}
+		}
+		return FormattedValue{Text: formatClock(v.Microseconds), Kind: KindTime}
+	case pgtype.Interval:
+		return FormattedValue{Text: formatInterval(v), Kind: KindTime}
+	case time.Duration:
+		return FormattedValue{Text: v.String(), Kind: KindTime}
+	case netip.Prefix:
+		// inet values that are a single address are shown without a mask.
+		if typeName == "inet" && v.IsSingleIP() {
+			return FormattedValue{Text: v.Addr().String(), Kind: KindText}
+		}
+		return FormattedValue{Text: v.String(), Kind: KindText}
+	case netip.Addr:
+		return FormattedValue{Text: v.String(), Kind: KindText}
+	case net.HardwareAddr:
+		return FormattedValue{Text: v.String(), Kind: KindText}
+	case map[string]any:
+		return formatJSON(v)
+	case []any:
+		if typeName == "json" || typeName == "jsonb" {
+			return formatJSON(v)
+		}
+		return FormattedValue{Text: formatArray(col, v), Kind: KindText}
+	case driver.Valuer:
+		return formatValuer(col, v, KindText)
+	case fmt.Stringer:
+		return FormattedValue{Text: v.String(), Kind: KindText}
+	default:
+		// JSON scalars and other values pgx has no special type for.
+		if typeName == "json" || typeName == "jsonb" {
+			return formatJSON(v)
+		}
+		return FormattedValue{Text: fmt.Sprintf("%v", v), Kind: KindText}
+	}
+}
+
+// isNumericType reports whether values of the named type are numbers, for
+// drivers that return decimals as strings.
+func isNumericType(typeName string) bool {
+	switch {
+	case strings.HasPrefix(typeName, "decimal"),
+		strings.HasPrefix(typeName, "numeric"),
+		strings.HasSuffix(typeName, "int"), typeName == "integer",
+		typeName == "real", typeName == "float", typeName == "double",
+		typeName == "double precision", typeName == "money":
+		return true
+	}
+
+	return false
+}
+
+func formatNumeric(n pgtype.Numeric) FormattedValue {
+	v, err := n.Value()
+	if err != nil || v == nil {
+		return FormattedValue{Text: "NULL", Kind: KindNull}
+	}
+
+	return FormattedValue{Text: fmt.Sprintf("%v", v), Kind: KindNumber}
+}
+
+// formatValuer renders values that know how to encode themselves, which
+// covers most of pgx's types for geometric, range and other values.
+func formatValuer(col Column, v driver.Valuer, kind ValueKind) FormattedValue {
+	dv, err := v.Value()
+	if err != nil {
+		return FormattedValue{Text: fmt.Sprintf("%v", v), Kind: kind}
+	}
+
+	if _, ok := dv.(driver.Valuer); ok || dv == nil {
+		if dv == nil {
+			return FormattedValue{Text: "NULL", Kind: KindNull}
+		}
+		return FormattedValue{Text: fmt.Sprintf("%v", dv), Kind: kind}
+	}
+
+	f := formatValue(col, dv)
+	if f.Kind == KindText {
+		f.Kind = kind
+	}
+
+	return f
+}
+
+func formatTime(typeName string, t time.Time) string {
+	switch {
+	case typeName == "date":
+		return t.Format(time.DateOnly)
+	case strings.HasPrefix(typeName, "timestamp with"), typeName == "timestamptz":
+		return t.Format("2006-01-02 15:04:05.999999-07:00")
+	default:
+		return t.Format("2006-01-02 15:04:05.999999")
+	}
+}
+
+// formatInterval renders an interval the way Postgres does, e.g.
+// "1 year 2 mons 3 days 04:05:06".
+func formatInterval(i pgtype.Interval) string {
+	if !i.Valid {
+		return "NULL"
+	}
+
+	plural := func(n int64, unit string) string {
+		if n == 1 || n == -1 {
+			return fmt.Sprintf("%d %s", n, unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	var parts []string
+
+	years, months := int64(i.Months/12), int64(i.Months%12)
+	if years != 0 {
+		parts = append(parts, plural(years, "year"))
+	}
+	if months != 0 {
+		parts = append(parts, plural(months, "mon"))
+	}
+	if i.Days != 0 {
+		parts = append(parts, plural(int64(i.Days), "day"))
+	}
+
+	if i.Microseconds != 0 || len(parts) == 0 {
+		parts = append(parts, formatClock(i.Microseconds))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatClock renders a number of microseconds as hh:mm:ss, with fractional
+// seconds only when there are any.
+func formatClock(micros int64) string {
+	sign := ""
+	if micros < 0 {
+		sign = "-"
+		micros = -micros
+	}
+
+	d := time.Duration(micros) * time.Microsecond
+	clock := fmt.Sprintf("%s%02d:%02d:%02d", sign, int64(d.Hours()), int64(d.Minutes())%60, int64(d.Seconds())%60)
+	if frac := micros % 1_000_000; frac != 0 {
+		clock += strings.TrimRight(fmt.Sprintf(".%06d", frac), "0")
+	}
+
+	return clock
+}
+
+func formatJSON(v any) FormattedValue {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return FormattedValue{Text: fmt.Sprintf("%v", v), Kind: KindJSON}
+	}
+
+	return FormattedValue{Text: string(b), Kind: KindJSON}
+}
+
+// formatArray renders an array as a Postgres array literal, e.g. {1,2,NULL}.
+func formatArray(col Column, values []any) string {
+	// Format elements as the array's element type, e.g. "date" for "date[]".
+	elem := col
+	elem.TypeName = strings.TrimSuffix(col.TypeName, "[]")
+
+	parts := make([]string, len(values))
+	for i, v := range values {
+		if nested, ok := v.([]any); ok {
+			parts[i] = formatArray(col, nested)
+			continue
+		}
+
+		f := formatValue(elem, v)
+		if f.Kind == KindNull {
+			parts[i] = "NULL"
+			continue
+		}
+
+		parts[i] = quoteArrayElement(f.Text)
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func quoteArrayElement(s string) string {
+	if s != "" && !strings.EqualFold(s, "NULL") && !strings.ContainsAny(s, "{},\"\\ \t\n") {
+		return s
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
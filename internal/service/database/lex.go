@@ -0,0 +1,226 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	// tokenWord is a keyword or an unquoted identifier.
+	tokenWord tokenKind = iota
+	// tokenQuoted is a string literal or a quoted identifier.
+	tokenQuoted
+	tokenNumber
+	// tokenParam is a numbered parameter such as $1.
+	tokenParam
+	// tokenPunct is any other character, such as an operator, a comma or a
+	// semicolon.
+	tokenPunct
+)
+
+// token is a lexical token of an SQL statement. Comments and whitespace are
+// not tokens.
+type token struct {
+	kind tokenKind
+	text string
+	// pos is the byte offset of the token in the query.
+	pos int
+	// depth is the number of parentheses the token is nested in.
+	depth int
+}
+
+// is reports whether the token is the keyword, ignoring case.
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// lex splits query into tokens. It understands enough of each dialect's
+// quoting to never mistake the contents of a string, quoted identifier or
+// comment for SQL: single quoted strings, double quoted and backquoted
+// identifiers, Postgres escape strings and dollar quoting, and line and
+// block comments. Unterminated quotes and comments run to the end of query.
+func (d Dialect) lex(query string) []token {
+	var tokens []token
+	depth := 0
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+			continue
+
+		case strings.HasPrefix(query[i:], "--") || (c == '#' && d == DialectMySQL):
+			i = indexFrom(query, i, "\n", 1)
+			continue
+
+		case strings.HasPrefix(query[i:], "/*"):
+			i = d.skipBlockComment(query, i)
+			continue
+
+		case c == '\'':
+			i = scanQuoted(query, i, '\'', d == DialectMySQL)
+
+		case c == '"' || (c == '`' && d == DialectMySQL):
+			i = scanQuoted(query, i, c, false)
+
+		case (c == 'E' || c == 'e') && d == DialectPostgres && i+1 < len(query) && query[i+1] == '\'':
+			i = scanQuoted(query, i+1, '\'', true)
+
+		case c == '$' && d == DialectPostgres:
+			if tag, ok := dollarTag(query[i:]); ok {
+				i = indexFrom(query, i, tag, len(tag))
+				break
+			}
+			i = scanWord(query, i+1)
+			tokens = append(tokens, token{kind: tokenParam, text: query[start:i], pos: start, depth: depth})
+			continue
+
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			i = scanNumber(query, i)
+			tokens = append(tokens, token{kind: tokenNumber, text: query[start:i], pos: start, depth: depth})
+			continue
+
+		case isWordStart(query[i:]):
+			i = scanWord(query, i)
+			tokens = append(tokens, token{kind: tokenWord, text: query[start:i], pos: start, depth: depth})
+			continue
+
+		default:
+			_, size := utf8.DecodeRuneInString(query[i:])
+			i += size
+
+			if c == ')' && depth > 0 {
+				depth--
+			}
+			tokens = append(tokens, token{kind: tokenPunct, text: query[start:i], pos: start, depth: depth})
+			if c == '(' {
+				depth++
+			}
+			continue
+		}
+
+		tokens = append(tokens, token{kind: tokenQuoted, text: query[start:i], pos: start, depth: depth})
+	}
+
+	return tokens
+}
+
+// indexFrom returns the offset just past the first end found after skipping
+// skip bytes from start, or the end of s if there is none.
+func indexFrom(s string, start int, end string, skip int) int {
+	from := min(start+skip, len(s))
+
+	i := strings.Index(s[from:], end)
+	if i < 0 {
+		return len(s)
+	}
+
+	return from + i + len(end)
+}
+
+// skipBlockComment returns the offset past the block comment at start.
+// Postgres block comments nest, MySQL and SQLite ones do not.
+func (d Dialect) skipBlockComment(query string, start int) int {
+	if d != DialectPostgres {
+		return indexFrom(query, start, "*/", 2)
+	}
+
+	depth := 0
+	for i := start; i < len(query)-1; i++ {
+		switch query[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return len(query)
+}
+
+// scanQuoted returns the offset past the quoted string or identifier at
+// start. Doubled quotes stand for a quote, and with backslashes set so does a
+// quote escaped with a backslash.
+func scanQuoted(s string, start int, quote byte, backslashes bool) int {
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case backslashes && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+
+	return len(s)
+}
+
+// dollarTag returns the dollar quote tag, such as "$$" or "$body$", that s
+// starts with.
+func dollarTag(s string) (string, bool) {
+	end := strings.IndexByte(s[1:], '$')
+	if end < 0 {
+		return "", false
+	}
+
+	tag := s[1 : end+1]
+	for i, r := range tag {
+		if !(r == '_' || unicode.IsLetter(r) || i > 0 && unicode.IsDigit(r)) {
+			return "", false
+		}
+	}
+
+	return s[:end+2], true
+}
+
+func isWordStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// scanWord returns the offset past the word at start.
+func scanWord(s string, start int) int {
+	i := start
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		i += size
+	}
+
+	return i
+}
+
+// scanNumber returns the offset past the number at start, including any
+// fraction and exponent.
+func scanNumber(s string, start int) int {
+	i := start
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9', c == '.', c == '_':
+		case (c == 'e' || c == 'E') && i+1 < len(s) && (s[i+1] >= '0' && s[i+1] <= '9' || s[i+1] == '+' || s[i+1] == '-'):
+			i++
+		default:
+			return i
+		}
+		i++
+	}
+
+	return i
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    []string
+	}{
+		{"words and punctuation", DialectPostgres, "SELECT a.b, count(*) FROM t;", []string{"SELECT", "a", ".", "b", ",", "count", "(", "*", ")", "FROM", "t", ";"}},
+		{"numbers", DialectPostgres, "SELECT 1, 2.5, .5, 1e-3, 1_000", []string{"SELECT", "1", ",", "2.5", ",", ".5", ",", "1e-3", ",", "1_000"}},
+		{"strings", DialectPostgres, "SELECT 'it''s', 'a;b'", []string{"SELECT", "'it''s'", ",", "'a;b'"}},
+		{"escape strings", DialectPostgres, `SELECT E'it\'s', 'a\'`, []string{"SELECT", `E'it\'s'`, ",", `'a\'`}},
+		{"dollar quotes", DialectPostgres, "SELECT $$a;'b$$, $tag$x$$y$tag$, $1", []string{"SELECT", "$$a;'b$$", ",", "$tag$x$$y$tag$", ",", "$1"}},
+		{"empty dollar quotes", DialectPostgres, "SELECT $$$$, $$a$$", []string{"SELECT", "$$$$", ",", "$$a$$"}},
+		{"unterminated dollar quote", DialectPostgres, "SELECT $$a; b", []string{"SELECT", "$$a; b"}},
+		{"quoted identifiers", DialectPostgres, `SELECT "a ""b"" c" FROM t`, []string{"SELECT", `"a ""b"" c"`, "FROM", "t"}},
+		{"line comments", DialectPostgres, "SELECT 1 -- a; b\n, 2", []string{"SELECT", "1", ",", "2"}},
+		{"nested comments", DialectPostgres, "SELECT /* a /* b; */ c; */ 1", []string{"SELECT", "1"}},
+		{"comments do not nest in MySQL", DialectMySQL, "SELECT /* a /* b */ c */ 1", []string{"SELECT", "c", "*", "/", "1"}},
+		{"hash comments", DialectMySQL, "SELECT 1 # a; b\n, 2", []string{"SELECT", "1", ",", "2"}},
+		{"hash is not a comment in Postgres", DialectPostgres, "SELECT a #- b", []string{"SELECT", "a", "#", "-", "b"}},
+		{"backquotes", DialectMySQL, "SELECT `a ``b`` c` FROM t", []string{"SELECT", "`a ``b`` c`", "FROM", "t"}},
+		{"backslash escapes", DialectMySQL, `SELECT 'it\'s', 'b'`, []string{"SELECT", `'it\'s'`, ",", "'b'"}},
+		{"no backslash escapes", DialectSQLite, `SELECT 'a\', 'b'`, []string{"SELECT", `'a\'`, ",", "'b'"}},
+		{"unterminated string", DialectSQLite, "SELECT 'a; b", []string{"SELECT", "'a; b"}},
+		{"casts", DialectPostgres, "SELECT a::text", []string{"SELECT", "a", ":", ":", "text"}},
+		{"named parameters", DialectPostgres, "SELECT :name", []string{"SELECT", ":", "name"}},
+		{"unicode", DialectPostgres, "SELECT café, 'é' → x", []string{"SELECT", "café", ",", "'é'", "→", "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := tt.dialect.lex(tt.query)
+
+			got := make([]string, len(tokens))
+			for i, tok := range tokens {
+				got[i] = tok.text
+				if tt.query[tok.pos:tok.pos+len(tok.text)] != tok.text {
+					t.Errorf("token %q is not at its position %d", tok.text, tok.pos)
+				}
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLexKinds(t *testing.T) {
+	tokens := DialectPostgres.lex(`SELECT "a", 'b', $$c$$, 1, $1, $name, ;`)
+
+	want := []tokenKind{
+		tokenWord, tokenQuoted, tokenPunct, tokenQuoted, tokenPunct, tokenQuoted, tokenPunct,
+		tokenNumber, tokenPunct, tokenParam, tokenPunct, tokenParam, tokenPunct, tokenPunct,
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(want))
+	}
+	for i, tok := range tokens {
+		if tok.kind != want[i] {
+			t.Errorf("token %q has kind %d, want %d", tok.text, tok.kind, want[i])
+		}
+	}
+}
+
+func TestLexDepth(t *testing.T) {
+	tokens := DialectPostgres.lex("f((a), b) c")
+
+	want := map[string]int{"f": 0, "a": 2, "b": 1, "c": 0}
+	for _, tok := range tokens {
+		if depth, ok := want[tok.text]; ok && tok.depth != depth {
+			t.Errorf("%q is at depth %d, want %d", tok.text, tok.depth, depth)
+		}
+	}
+
+	// Parentheses are at the depth outside of them.
+	if tokens[1].depth != 0 || tokens[len(tokens)-2].depth != 0 {
+		t.Errorf("outer parentheses at depths %d and %d, want 0", tokens[1].depth, tokens[len(tokens)-2].depth)
+	}
+}
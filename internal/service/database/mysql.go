@@ -0,0 +1,358 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+
+	"github.com/davesavic/lazydb/internal/service/config"
+	"github.com/go-sql-driver/mysql"
+)
+
+var _ DatabaseIntegration = (*MySQL)(nil)
+
+// MySQLDriverName is the connection type the MySQL driver is registered under.
+const MySQLDriverName = "mysql"
+
+func init() {
+	Register(MySQLDriverName, func() DatabaseIntegration { return NewMySQL() })
+}
+
+// MySQL is a DatabaseIntegration for MySQL and MariaDB servers.
+type MySQL struct {
+	sqlConn
+}
+
+// Name implements DatabaseIntegration.
+func (m *MySQL) Name() string {
+	return MySQLDriverName
+}
+
+func NewMySQL() *MySQL {
+	return &MySQL{}
+}
+
+func (m *MySQL) Connect(connCfg config.ConnectionConfig) error {
+	cfg := mysql.NewConfig()
+	cfg.User = connCfg.User
+	cfg.Passwd = connCfg.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(connCfg.Host, connCfg.Port)
+	cfg.DBName = connCfg.Database
+	cfg.ParseTime = true
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return fmt.Errorf("could not connect to database: %w", err)
+	}
+
+	// The connection that replaces a broken one has to be made read-only
+	// too, as the setting only applies to the connection it is made on.
+	if connCfg.ReadOnly {
+		connector = &initConnector{Connector: connector, query: "SET SESSION TRANSACTION READ ONLY"}
+	}
+
+	m.pin(sql.OpenDB(connector))
+
+	err = m.db.PingContext(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not ping database: %w", err)
+	}
+
+	return nil
+}
+
+func (m *MySQL) GetTables() ([]string, error) {
+	rows, err := m.queryContext(context.Background(), "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()")
+	if err != nil {
+		return nil, fmt.Errorf("could not get tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		err = rows.Scan(&table)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan table: %w", err)
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+func (m *MySQL) Run(ctx context.Context, query string, args ...any) (*QueryResult, error) {
+	if !m.Dialect().returnsRows(query) {
+		return m.exec(ctx, m.Dialect(), query, args...)
+	}
+
+	table := lookupSQLTable(ctx, m.Dialect(), query, m.tableColumns)
+
+	rows, err := m.queryContext(ctx, query, args...)
+	if err != nil {
+		return nil, newQueryError(query, err)
+	}
+
+	result, err := newSQLQueryResult(query, rows, mysqlValue)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Source = table.source(result.Columns)
+
+	return result, nil
+}
+
+// ExecTx implements DatabaseIntegration.
+func (m *MySQL) ExecTx(ctx context.Context, statements []Statement) error {
+	return m.execTx(ctx, statements)
+}
+
+// Begin implements DatabaseIntegration.
+func (m *MySQL) Begin(context.Context) error {
+	return m.begin()
+}
+
+// Commit implements DatabaseIntegration.
+func (m *MySQL) Commit(context.Context) error {
+	return m.commit()
+}
+
+// Rollback implements DatabaseIntegration.
+func (m *MySQL) Rollback(context.Context) error {
+	return m.rollback()
+}
+
+// EstimateRows implements DatabaseIntegration. It adds up the rows the
+// optimizer expects to read from each table of the plan.
+func (m *MySQL) EstimateRows(ctx context.Context, query string) (int64, error) {
+	rows, err := m.queryContext(ctx, "EXPLAIN "+query)
+	if err != nil {
+		return 0, fmt.Errorf("could not explain query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("could not explain query: %w", err)
+	}
+
+	column := slices.Index(columns, "rows")
+	if column < 0 {
+		return 0, fmt.Errorf("could not explain query: %w", ErrNotSupported)
+	}
+
+	var estimate sql.NullInt64
+	values := make([]any, len(columns))
+	for i := range values {
+		values[i] = new(any)
+	}
+	values[column] = &estimate
+
+	var total int64
+	for rows.Next() {
+		err = rows.Scan(values...)
+		if err != nil {
+			return 0, fmt.Errorf("could not read query plan: %w", err)
+		}
+
+		total += estimate.Int64
+	}
+
+	return total, rows.Err()
+}
+
+// Catalog implements DatabaseIntegration. Only the tables and functions of
+// the connection's database are listed.
+func (m *MySQL) Catalog(ctx context.Context) (*Catalog, error) {
+	return loadCatalog(ctx, catalogQueries{
+		schemas: "SELECT schema_name FROM information_schema.schemata ORDER BY schema_name",
+		columns: `
+			SELECT table_schema, table_name, column_name FROM information_schema.columns
+			WHERE table_schema = DATABASE()
+			ORDER BY table_name, ordinal_position`,
+		functions: `
+			SELECT DISTINCT routine_name FROM information_schema.routines
+			WHERE routine_schema = DATABASE()
+			ORDER BY routine_name`,
+	}, m.scanRows)
+}
+
+// Objects implements DatabaseIntegration. The databases on the server are
+// listed as schemas, which is what MySQL takes them to be.
+func (m *MySQL) Objects(ctx context.Context, path []Object) ([]Object, error) {
+	switch len(path) {
+	case 0:
+		return loadObjects(ctx, m.scanRows, nil,
+			"SELECT 'schema', schema_name, '' FROM information_schema.schemata ORDER BY schema_name")
+
+	case 1:
+		return loadObjects(ctx, m.scanRows, []any{path[0].Name}, `
+			SELECT IF(table_type = 'VIEW', 'view', 'table'), table_name, ''
+			FROM information_schema.tables
+			WHERE table_schema = ?
+			ORDER BY table_name`, `
+			SELECT 'function', routine_name, IF(routine_type = 'PROCEDURE', 'procedure', '')
+			FROM information_schema.routines
+			WHERE routine_schema = ?
+			ORDER BY routine_name`)
+
+	case 2:
+		// The statistics have a row for each column of an index.
+		return loadObjects(ctx, m.scanRows, []any{path[0].Name, path[1].Name}, `
+			SELECT 'column', column_name, CONCAT(column_type, IF(is_nullable = 'NO', ' not null', ''))
+			FROM information_schema.columns
+			WHERE table_schema = ? AND table_name = ?
+			ORDER BY ordinal_position`, `
+			SELECT 'index', index_name, IF(index_name = 'PRIMARY', 'primary', IF(non_unique = 0, 'unique', ''))
+			FROM information_schema.statistics
+			WHERE table_schema = ? AND table_name = ?
+			GROUP BY index_name, non_unique
+			ORDER BY index_name`, `
+			SELECT 'constraint', constraint_name, LOWER(constraint_type)
+			FROM information_schema.table_constraints
+			WHERE table_schema = ? AND table_name = ?
+			ORDER BY constraint_name`)
+	}
+
+	return nil, nil
+}
+
+// Structure implements DatabaseIntegration. An empty schema means the
+// connection's database. Indexes are described by their columns, as MySQL
+// does not keep the statements that created them.
+func (m *MySQL) Structure(ctx context.Context, schema, table string) (*TableStructure, error) {
+	structure, err := loadStructure(ctx, structureQueries{
+		columns: `
+			SELECT column_name, column_type, is_nullable = 'YES',
+				CONCAT_WS(' ', column_default, NULLIF(extra, '')), column_comment
+			FROM information_schema.columns
+			WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+			ORDER BY ordinal_position`,
+		primaryKey: `
+			SELECT column_name FROM information_schema.key_column_usage
+			WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ? AND constraint_name = 'PRIMARY'
+			ORDER BY ordinal_position`,
+		foreignKeys: `
+			SELECT k.constraint_name, k.constraint_name, k.column_name,
+				k.referenced_table_schema, k.referenced_table_name, k.referenced_column_name,
+				r.update_rule, r.delete_rule
+			FROM information_schema.key_column_usage k
+				JOIN information_schema.referential_constraints r
+					ON r.constraint_schema = k.constraint_schema AND r.constraint_name = k.constraint_name
+			WHERE k.table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND k.table_name = ?
+			ORDER BY k.constraint_name, k.ordinal_position`,
+		indexes: `
+			SELECT index_name, non_unique = 0, index_name = 'PRIMARY',
+				CONCAT(IF(non_unique = 0, 'UNIQUE ', ''), 'INDEX ', index_name,
+					' (', COALESCE(GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ', '), ''), ') USING ', index_type)
+			FROM information_schema.statistics
+			WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+			GROUP BY index_name, non_unique, index_type
+			ORDER BY index_name`,
+		checks: `
+			SELECT c.constraint_name, CONCAT('CHECK (', c.check_clause, ')')
+			FROM information_schema.table_constraints t
+				JOIN information_schema.check_constraints c
+					ON c.constraint_schema = t.constraint_schema AND c.constraint_name = t.constraint_name
+			WHERE t.table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND t.table_name = ? AND t.constraint_type = 'CHECK'
+			ORDER BY c.constraint_name`,
+		triggers: `
+			SELECT trigger_name, CONCAT(action_timing, ' ', event_manipulation, ' FOR EACH ROW ', action_statement)
+			FROM information_schema.triggers
+			WHERE event_object_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND event_object_table = ?
+			ORDER BY action_order`,
+	}, []any{schema, table}, m.scanRows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(structure.Columns) == 0 {
+		return nil, fmt.Errorf("could not find table %q", table)
+	}
+
+	return structure, nil
+}
+
+// Dialect implements DatabaseIntegration.
+func (m *MySQL) Dialect() Dialect {
+	return DialectMySQL
+}
+
+// tableColumns implements the lookup of lookupSQLTable. An empty schema
+// means the connection's database.
+func (m *MySQL) tableColumns(ctx context.Context, schema, table string) ([]TableColumn, error) {
+	rows, err := m.queryContext(ctx, `
+		SELECT column_name, column_type, is_nullable = 'YES', column_key = 'PRI',
+			CASE WHEN extra LIKE '%auto_increment%' THEN 'auto_increment' ELSE COALESCE(column_default, '') END,
+			extra LIKE '%GENERATED%'
+		FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+		ORDER BY ordinal_position`, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("could not get table columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []TableColumn
+	for rows.Next() {
+		var col TableColumn
+		err = rows.Scan(&col.Name, &col.TypeName, &col.Nullable, &col.PrimaryKey, &col.Default, &col.Generated)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan table column: %w", err)
+		}
+
+		columns = append(columns, col)
+	}
+
+	return columns, rows.Err()
+}
+
+func (m *MySQL) Close() error {
+	if m.db == nil {
+		return nil
+	}
+
+	if m.tx != nil {
+		_ = m.tx.Rollback()
+	}
+
+	return m.db.Close()
+}
+
+// mysqlValue converts a value scanned from the MySQL driver into a Go value.
+// The text protocol returns most columns as raw bytes, so these are parsed
+// according to the column's database type.
+func mysqlValue(typeName string, value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		switch typeName {
+		case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR":
+			if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+				return i
+			}
+		case "UNSIGNED TINYINT", "UNSIGNED SMALLINT", "UNSIGNED MEDIUMINT", "UNSIGNED INT", "UNSIGNED BIGINT":
+			if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+				return u
+			}
+		case "FLOAT", "DOUBLE":
+			if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+				return f
+			}
+		case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BIT", "GEOMETRY":
+			return v
+		}
+
+		// DECIMAL is kept as a string to avoid losing precision.
+		return string(v)
+	default:
+		return v
+	}
+}
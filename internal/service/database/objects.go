@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// ObjectKind is the kind of an Object.
+type ObjectKind string
+
+const (
+	ObjectDatabase         ObjectKind = "database"
+	ObjectSchema           ObjectKind = "schema"
+	ObjectTable            ObjectKind = "table"
+	ObjectView             ObjectKind = "view"
+	ObjectMaterializedView ObjectKind = "materialized view"
+	ObjectSequence         ObjectKind = "sequence"
+	ObjectFunction         ObjectKind = "function"
+	ObjectColumn           ObjectKind = "column"
+	ObjectIndex            ObjectKind = "index"
+	ObjectConstraint       ObjectKind = "constraint"
+)
+
+// Container reports whether objects of the kind contain other objects, which
+// DatabaseIntegration.Objects lists.
+func (k ObjectKind) Container() bool {
+	switch k {
+	case ObjectDatabase, ObjectSchema, ObjectTable, ObjectView, ObjectMaterializedView:
+		return true
+	}
+
+	return false
+}
+
+// Object is an object of a database, such as a schema, a table or one of its
+// columns.
+type Object struct {
+	Kind ObjectKind
+	Name string
+	// Detail describes the object in a few words, such as the type of a
+	// column or the arguments of a function.
+	Detail string
+}
+
+// loadObjects lists objects with queries that return the kind, name and
+// detail of each object, in the order they are listed. Each query is run
+// with args.
+func loadObjects(ctx context.Context, scanRows scanRowsFunc, args []any, queries ...string) ([]Object, error) {
+	var objects []Object
+	for _, query := range queries {
+		err := scanRows(ctx, query, args, func(scan func(...any) error) error {
+			var object Object
+			if err := scan(&object.Kind, &object.Name, &object.Detail); err != nil {
+				return err
+			}
+
+			objects = append(objects, object)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get objects: %w", err)
+		}
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// param is a placeholder of a query that an argument is bound to.
+type param struct {
+	// name is the name of a :name parameter without its colon, or the
+	// placeholder of a positional one, such as "$1", or "?2" for the second
+	// question mark.
+	name string
+	// named is set for :name parameters, which are replaced with the
+	// dialect's placeholders when bound.
+	named bool
+	// start and end are the byte offsets of the placeholder.
+	start int
+	end   int
+}
+
+// params finds the placeholders of query: :name parameters, and $1 in
+// Postgres or ? in MySQL and SQLite. Placeholders in strings and comments,
+// Postgres casts such as ::text and MySQL assignments such as := are not
+// parameters.
+func (d Dialect) params(query string) []param {
+	tokens := d.lex(query)
+
+	var params []param
+	questions := 0
+	for i, t := range tokens {
+		switch {
+		case t.kind == tokenParam && isParamNumber(t.text[1:]):
+			params = append(params, param{name: t.text, start: t.pos, end: t.pos + len(t.text)})
+
+		case t.kind == tokenPunct && t.text == "?" && d != DialectPostgres:
+			questions++
+			params = append(params, param{name: "?" + strconv.Itoa(questions), start: t.pos, end: t.pos + 1})
+
+		case t.kind == tokenPunct && t.text == ":" && i+1 < len(tokens):
+			next := tokens[i+1]
+			if next.kind != tokenWord || next.pos != t.pos+1 {
+				continue
+			}
+			if prev := i - 1; prev >= 0 && tokens[prev].text == ":" && tokens[prev].pos == t.pos-1 {
+				continue
+			}
+
+			params = append(params, param{name: next.text, named: true, start: t.pos, end: next.pos + len(next.text)})
+		}
+	}
+
+	return params
+}
+
+// Params returns the names of the parameters of query, in the order they
+// first appear: the names of :name parameters without their colon, and the
+// placeholders of positional ones, such as "$1", or "?2" for the second
+// question mark.
+func (d Dialect) Params(query string) []string {
+	var names []string
+	for _, p := range d.params(query) {
+		if !slices.Contains(names, p.name) {
+			names = append(names, p.name)
+		}
+	}
+
+	return names
+}
+
+// Bind returns query with its :name parameters replaced with the dialect's
+// placeholders, and the arguments to run it with, taken from values by the
+// names Params returns. Positional placeholders are kept as they are.
+func (d Dialect) Bind(query string, values map[string]any) (string, []any) {
+	params := d.params(query)
+	placeholder, args := d.bindParams(params, values)
+
+	return replaceParams(query, params, placeholder), args
+}
+
+// Unbind maps pos, the 1-based character position of an error in the query
+// Bind returns for query, to the position of the same character in query. A
+// position inside a placeholder maps to the start of the parameter it
+// replaced.
+func (d Dialect) Unbind(query string, pos int) int {
+	if pos <= 0 {
+		return pos
+	}
+
+	params := d.params(query)
+	placeholder, _ := d.bindParams(params, nil)
+	bound := replaceParams(query, params, placeholder)
+
+	// Positions count characters, the parameters' offsets count bytes.
+	offset := len(bound)
+	for i := range bound {
+		if pos--; pos == 0 {
+			offset = i
+			break
+		}
+	}
+
+	shift := 0
+	for _, p := range params {
+		if !p.named {
+			continue
+		}
+
+		start := p.start + shift
+		n := len(placeholder(p))
+		if offset < start {
+			break
+		}
+		if offset < start+n {
+			shift = start - p.start
+			offset = start
+			break
+		}
+		shift += n - (p.end - p.start)
+	}
+
+	return utf8.RuneCountInString(query[:min(offset-shift, len(query))]) + 1
+}
+
+// bindParams returns the placeholder each of params is replaced with, and
+// the arguments taken from values.
+func (d Dialect) bindParams(params []param, values map[string]any) (func(param) string, []any) {
+	// Numbered placeholders can refer to the same argument more than once,
+	// so each name is bound once, after the numbers already in use.
+	if d == DialectPostgres {
+		var args []any
+		numbers := make(map[string]int)
+		for _, p := range params {
+			if !p.named {
+				n, _ := strconv.Atoi(p.name[1:])
+				numbers[p.name] = n
+				args = append(args, make([]any, max(n-len(args), 0))...)
+				args[n-1] = values[p.name]
+			}
+		}
+		for _, p := range params {
+			if _, ok := numbers[p.name]; p.named && !ok {
+				args = append(args, values[p.name])
+				numbers[p.name] = len(args)
+			}
+		}
+
+		return func(p param) string {
+			return d.placeholder(numbers[p.name])
+		}, args
+	}
+
+	args := make([]any, len(params))
+	for i, p := range params {
+		args[i] = values[p.name]
+	}
+
+	return func(param) string {
+		return d.placeholder(0)
+	}, args
+}
+
+// replaceParams replaces the :name parameters of query with placeholder.
+func replaceParams(query string, params []param, placeholder func(param) string) string {
+	var b strings.Builder
+	last := 0
+	for _, p := range params {
+		if !p.named {
+			continue
+		}
+
+		b.WriteString(query[last:p.start])
+		b.WriteString(placeholder(p))
+		last = p.end
+	}
+	b.WriteString(query[last:])
+
+	return b.String()
+}
+
+// maxParamNumber is the largest $1 placeholder Postgres accepts. Larger
+// numbers are left for the server to reject, rather than bound to a slice of
+// arguments that long.
+const maxParamNumber = 65535
+
+// isParamNumber reports whether s is the number of a $1 placeholder.
+func isParamNumber(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0 && n <= maxParamNumber && s[0] != '+'
+}
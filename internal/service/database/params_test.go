@@ -0,0 +1,140 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    []string
+	}{
+		{DialectPostgres, "SELECT * FROM t WHERE a = :a AND b = :b OR a = :a", []string{"a", "b"}},
+		{DialectPostgres, "SELECT :a::int, a::text", []string{"a"}},
+		{DialectPostgres, "SELECT ':a', \":b\", $$:c$$, $t$ :d $t$ -- :e\n/* :f */ FROM t", nil},
+		{DialectPostgres, "SELECT : a, x:y", []string{"y"}},
+		{DialectPostgres, "SELECT a[1:2], a[:n]", []string{"n"}},
+		{DialectMySQL, "SET @n := 1; SELECT `:a`, \":b\", :n # :c", []string{"n"}},
+		{DialectSQLite, "SELECT 1", nil},
+
+		// Positional placeholders.
+		{DialectPostgres, "SELECT :a::int, $1, $2, $1", []string{"a", "$1", "$2"}},
+		{DialectPostgres, "SELECT $1::int, $0, $01x", []string{"$1"}},
+		{DialectPostgres, "SELECT $65535, $65536, $2000000000", []string{"$65535"}},
+		{DialectPostgres, "SELECT '$1', $t$ $2 $t$ -- $3\n/* ? */, ?", nil},
+		{DialectMySQL, "SELECT ?, :a, ? FROM t WHERE c = '?'", []string{"?1", "a", "?2"}},
+		{DialectMySQL, "SELECT `?`, \"?\" # ?", nil},
+		{DialectSQLite, "SELECT ? /* ? */, '?', ?", []string{"?1", "?2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := tt.dialect.Params(tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		dialect   Dialect
+		query     string
+		values    map[string]any
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			DialectPostgres, "SELECT :a, :b::text, :a", map[string]any{"a": 1, "b": "x"},
+			"SELECT $1, $2::text, $1", []any{1, "x"},
+		},
+		{
+			DialectPostgres, "SELECT ':a', x::int -- :a", nil,
+			"SELECT ':a', x::int -- :a", nil,
+		},
+		{
+			// Without numbered placeholders, a name is bound each time it appears.
+			DialectMySQL, "SELECT :a, :b, :a", map[string]any{"a": 1, "b": 2},
+			"SELECT ?, ?, ?", []any{1, 2, 1},
+		},
+		{
+			DialectMySQL, "SELECT @n := :n", map[string]any{"n": 1},
+			"SELECT @n := ?", []any{1},
+		},
+		{
+			DialectSQLite, "SELECT :é", map[string]any{"é": 1},
+			"SELECT ?", []any{1},
+		},
+		{
+			// Names are numbered after the placeholders already in use.
+			DialectPostgres, "SELECT :a, $2, $1", map[string]any{"a": "a", "$1": 1, "$2": 2},
+			"SELECT $3, $2, $1", []any{1, 2, "a"},
+		},
+		{
+			DialectPostgres, "SELECT $2", map[string]any{"$2": 2},
+			"SELECT $2", []any{nil, 2},
+		},
+		{
+			// Numbers Postgres does not accept are not bound.
+			DialectPostgres, "SELECT $1, $2000000000, :a", map[string]any{"$1": 1, "a": "a"},
+			"SELECT $1, $2000000000, $2", []any{1, "a"},
+		},
+		{
+			DialectMySQL, "SELECT :a, ?, :a, '?'", map[string]any{"a": 1, "?1": 2},
+			"SELECT ?, ?, ?, '?'", []any{1, 2, 1},
+		},
+		{
+			DialectSQLite, "SELECT :a, ?", map[string]any{"a": 1},
+			"SELECT ?, ?", []any{1, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, args := tt.dialect.Bind(tt.query, tt.values)
+			if query != tt.wantQuery {
+				t.Errorf("query = %q, want %q", query, tt.wantQuery)
+			}
+			if !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestUnbind(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		// pos is a position in the bound query, and want the position it
+		// maps to in query.
+		pos  int
+		want int
+	}{
+		{DialectPostgres, "SELECT 1", 8, 8},
+		{DialectPostgres, "SELECT 1", 0, 0},
+		// SELECT $1 FROM t WHERE a = $2 x
+		{DialectPostgres, "SELECT :name FROM t WHERE a = :other x", 8, 8},
+		{DialectPostgres, "SELECT :name FROM t WHERE a = :other x", 9, 8},
+		{DialectPostgres, "SELECT :name FROM t WHERE a = :other x", 11, 14},
+		{DialectPostgres, "SELECT :name FROM t WHERE a = :other x", 31, 38},
+		// SELECT ? FROM t WHERE a = ? x
+		{DialectMySQL, "SELECT :name FROM t WHERE a = :other x", 8, 8},
+		{DialectMySQL, "SELECT :name FROM t WHERE a = :other x", 10, 14},
+		{DialectMySQL, "SELECT :name FROM t WHERE a = :other x", 29, 38},
+		// Positions count characters, not bytes: SELECT 'é', $1, ✗
+		{DialectPostgres, "SELECT 'é', :name, ✗", 17, 20},
+		// Positional placeholders are not rewritten: SELECT $1, $2 x
+		{DialectPostgres, "SELECT $1, :a x", 15, 15},
+		{DialectPostgres, "SELECT $1, :a x", 13, 12},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.Unbind(tt.query, tt.pos); got != tt.want {
+			bound, _ := tt.dialect.Bind(tt.query, nil)
+			t.Errorf("Unbind(%q, %d) = %d, want %d (bound %q)", tt.query, tt.pos, got, tt.want, bound)
+		}
+	}
+}
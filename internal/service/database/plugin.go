@@ -0,0 +1,248 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/davesavic/lazydb/internal/service/config"
+	lazyplugin "github.com/davesavic/lazydb/pkg/plugin"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+)
+
+var _ DatabaseIntegration = (*Plugin)(nil)
+
+// Plugin is a DatabaseIntegration served by an external plugin binary.
+type Plugin struct {
+	name   string
+	path   string
+	client *plugin.Client
+	db     lazyplugin.Database
+}
+
+// Name implements DatabaseIntegration.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+func NewPlugin(name, path string) *Plugin {
+	return &Plugin{
+		name: name,
+		path: path,
+	}
+}
+
+// LoadPlugins registers a driver for every plugin binary in dir. Binaries are
+// named "lazydb-plugin-<type>" and are registered under "<type>". A missing
+// directory is not an error.
+func LoadPlugins(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read plugins directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), lazyplugin.BinaryPrefix)
+		if !ok || name == "" || entry.IsDir() {
+			continue
+		}
+
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		path := filepath.Join(dir, entry.Name())
+
+		registryMu.RLock()
+		_, exists := registry[strings.ToLower(name)]
+		registryMu.RUnlock()
+
+		if exists {
+			slog.Warn("database.LoadPlugins: driver already registered", "name", name, "path", path)
+			continue
+		}
+
+		slog.Debug("database.LoadPlugins", "name", name, "path", path)
+		Register(name, func() DatabaseIntegration { return NewPlugin(name, path) })
+	}
+
+	return nil
+}
+
+func (p *Plugin) Connect(connCfg config.ConnectionConfig) error {
+	p.client = plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  lazyplugin.Handshake,
+		Plugins:          plugin.PluginSet{lazyplugin.Name: &lazyplugin.GRPCPlugin{}},
+		Cmd:              exec.Command(p.path),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+		// go-plugin logs to stderr by default, which would draw over the UI.
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:   "plugin." + p.name,
+			Output: slogWriter{},
+			Level:  hclog.Debug,
+		}),
+	})
+
+	rpcClient, err := p.client.Client()
+	if err != nil {
+		p.client.Kill()
+		return fmt.Errorf("could not start plugin: %w", err)
+	}
+
+	raw, err := rpcClient.Dispense(lazyplugin.Name)
+	if err != nil {
+		p.client.Kill()
+		return fmt.Errorf("could not dispense plugin: %w", err)
+	}
+
+	p.db = raw.(lazyplugin.Database)
+
+	err = p.db.Connect(lazyplugin.Config{
+		Host:     connCfg.Host,
+		Port:     connCfg.Port,
+		Database: connCfg.Database,
+		User:     connCfg.User,
+		Password: connCfg.Password,
+		Path:     connCfg.Path,
+		ReadOnly: connCfg.ReadOnly,
+	})
+	if err != nil {
+		p.client.Kill()
+		return fmt.Errorf("could not connect to database: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Plugin) GetTables() ([]string, error) {
+	tables, err := p.db.GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("could not get tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// Run implements DatabaseIntegration.
+func (p *Plugin) Run(ctx context.Context, query string, args ...any) (*QueryResult, error) {
+	res, err := p.db.Run(ctx, query, args)
+	if err != nil {
+		return nil, newQueryError(query, err)
+	}
+
+	columns := make([]Column, len(res.Columns))
+	for i, name := range res.Columns {
+		columns[i] = Column{Name: name}
+	}
+
+	// Plugins return the whole result set at once.
+	return NewQueryResult(columns, &sliceRowSource{rows: res.Rows}), nil
+}
+
+// ExecTx implements DatabaseIntegration. The plugin protocol has no
+// transactions, so plugin result sets are never editable.
+func (p *Plugin) ExecTx(context.Context, []Statement) error {
+	return ErrNotSupported
+}
+
+// Begin implements DatabaseIntegration.
+func (p *Plugin) Begin(context.Context) error {
+	return ErrNotSupported
+}
+
+// Commit implements DatabaseIntegration.
+func (p *Plugin) Commit(context.Context) error {
+	return ErrNotSupported
+}
+
+// Rollback implements DatabaseIntegration.
+func (p *Plugin) Rollback(context.Context) error {
+	return ErrNotSupported
+}
+
+// EstimateRows implements DatabaseIntegration. The plugin protocol has no
+// way to explain a query.
+func (p *Plugin) EstimateRows(context.Context, string) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// Catalog implements DatabaseIntegration. The plugin protocol only lists
+// tables, so their columns are not known.
+func (p *Plugin) Catalog(context.Context) (*Catalog, error) {
+	tables, err := p.GetTables()
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{Tables: make([]CatalogTable, len(tables))}
+	for i, table := range tables {
+		catalog.Tables[i] = CatalogTable{Name: table}
+	}
+
+	return catalog, nil
+}
+
+// Objects implements DatabaseIntegration. The plugin protocol only lists
+// tables, so they are listed without a schema and nothing inside them.
+func (p *Plugin) Objects(_ context.Context, path []Object) ([]Object, error) {
+	if len(path) > 0 {
+		return nil, nil
+	}
+
+	tables, err := p.GetTables()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(tables)
+
+	objects := make([]Object, len(tables))
+	for i, table := range tables {
+		objects[i] = Object{Kind: ObjectTable, Name: table}
+	}
+
+	return objects, nil
+}
+
+// Structure implements DatabaseIntegration. The plugin protocol has no way
+// to describe a table.
+func (p *Plugin) Structure(context.Context, string, string) (*TableStructure, error) {
+	return nil, ErrNotSupported
+}
+
+// Dialect implements DatabaseIntegration. Plugins are assumed to accept
+// standard SQL, which quotes identifiers the way Postgres does.
+func (p *Plugin) Dialect() Dialect {
+	return DialectPostgres
+}
+
+func (p *Plugin) Close() error {
+	if p.client == nil {
+		return nil
+	}
+	defer p.client.Kill()
+
+	if p.db == nil {
+		return nil
+	}
+
+	return p.db.Close()
+}
+
+// slogWriter forwards plugin log output to the application log.
+type slogWriter struct{}
+
+var _ io.Writer = slogWriter{}
+
+func (slogWriter) Write(b []byte) (int, error) {
+	slog.Debug(strings.TrimSpace(string(b)))
+	return len(b), nil
+}
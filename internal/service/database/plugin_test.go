@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/davesavic/lazydb/internal/service/config"
+	lazyplugin "github.com/davesavic/lazydb/pkg/plugin"
+)
+
+// connectCSVPlugin builds the reference CSV plugin and connects it to a
+// directory holding a people table.
+func connectCSVPlugin(t *testing.T) DatabaseIntegration {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("building the plugin is slow")
+	}
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+
+	path := filepath.Join(t.TempDir(), lazyplugin.BinaryPrefix+"csv")
+	build := exec.Command(goBin, "build", "-o", path, "github.com/davesavic/lazydb/cmd/lazydb-plugin-csv")
+	out, err := build.CombinedOutput()
+	if err != nil {
+		t.Fatalf("could not build plugin: %v\n%s", err, out)
+	}
+
+	data := t.TempDir()
+	err = os.WriteFile(filepath.Join(data, "people.csv"), []byte("id,name\n1,Ada\n2,Grace\n3,Linus\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := NewPlugin("csv", path)
+	err = db.Connect(config.ConnectionConfig{Type: "csv", Path: data})
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func TestPluginHandshake(t *testing.T) {
+	ctx := context.Background()
+	db := connectCSVPlugin(t)
+
+	tables, err := db.GetTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || tables[0] != "people" {
+		t.Errorf("GetTables = %v, want [people]", tables)
+	}
+
+	result, err := db.Run(ctx, "SELECT * FROM people LIMIT 2")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(result.Columns) != 2 || result.Columns[1].Name != "name" {
+		t.Errorf("Columns = %+v, want id and name", result.Columns)
+	}
+
+	err = result.Fetch(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 1 || !result.HasMore {
+		t.Fatalf("got %d rows, more %v, want 1 row and more", len(result.Rows), result.HasMore)
+	}
+
+	err = result.Fetch(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 2 || result.HasMore {
+		t.Fatalf("got %d rows, more %v, want 2 rows and no more", len(result.Rows), result.HasMore)
+	}
+	if got := result.Rows[1][1]; got != "Grace" {
+		t.Errorf("second name = %v, want Grace", got)
+	}
+	if result.Source != nil {
+		t.Error("plugin result is editable")
+	}
+
+	// Arguments are bound through the protocol.
+	result, err = db.Run(ctx, "SELECT * FROM people LIMIT $1", int64(1))
+	if err != nil {
+		t.Fatalf("Run with arguments: %v", err)
+	}
+	err = result.Fetch(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 1 {
+		t.Errorf("got %d rows, want 1", len(result.Rows))
+	}
+
+	// Errors of the plugin come back through the protocol.
+	_, err = db.Run(ctx, "SELECT * FROM missing")
+	if err == nil {
+		t.Error("Run of a missing table succeeded")
+	}
+}
+
+func TestPluginNotSupported(t *testing.T) {
+	ctx := context.Background()
+	db := connectCSVPlugin(t)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"ExecTx", func() error { return db.ExecTx(ctx, nil) }},
+		{"Begin", func() error { return db.Begin(ctx) }},
+		{"Commit", func() error { return db.Commit(ctx) }},
+		{"Rollback", func() error { return db.Rollback(ctx) }},
+		{"EstimateRows", func() error {
+			_, err := db.EstimateRows(ctx, "SELECT * FROM people")
+			return err
+		}},
+		{"Structure", func() error {
+			_, err := db.Structure(ctx, "", "people")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, ErrNotSupported) {
+				t.Errorf("got %v, want ErrNotSupported", err)
+			}
+		})
+	}
+}
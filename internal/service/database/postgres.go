@@ -2,34 +2,74 @@ package database
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/davesavic/lazydb/internal/service/config"
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
 )
 
 var _ DatabaseIntegration = (*Postgres)(nil)
 
+// PostgresDriverName is the connection type the Postgres driver is registered under.
+const PostgresDriverName = "postgres"
+
+func init() {
+	Register(PostgresDriverName, func() DatabaseIntegration { return NewPostgres() })
+}
+
 type Postgres struct {
 	conn *pgx.Conn
+	// tx is the transaction opened by Begin, if any. Queries run on conn
+	// are part of it.
+	tx pgx.Tx
+	// typeNames caches the names of types by OID, which includes user
+	// defined types such as enums that pgx does not know about.
+	typeNames map[uint32]string
 }
 
-// Name implements DatabasePlugin.
+// Name implements DatabaseIntegration.
 func (p *Postgres) Name() string {
-	panic("unimplemented")
+	return PostgresDriverName
 }
 
 func NewPostgres() *Postgres {
-	return &Postgres{}
+	return &Postgres{
+		typeNames: make(map[uint32]string),
+	}
 }
 
 func (p *Postgres) Connect(connCfg config.ConnectionConfig) error {
-	conn, err := pgx.Connect(
-		context.Background(),
+	pgCfg, err := pgx.ParseConfig(
 		fmt.Sprintf("postgres://%s:%s@%s:%s/%s", connCfg.User, connCfg.Password, connCfg.Host, connCfg.Port, connCfg.Database),
 	)
+	if err != nil {
+		return fmt.Errorf("could not parse connection config: %w", err)
+	}
+
+	// Read-only connections cannot write in any transaction, including the
+	// ones opened by ExecTx and Begin.
+	if connCfg.ReadOnly {
+		pgCfg.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+
+	// Cancelling a query's context sends a cancel request to the server
+	// instead of closing the connection. The deadline is only a fallback for
+	// when the server does not respond to the cancel request.
+	pgCfg.BuildContextWatcherHandler = func(pgConn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{
+			Conn:          pgConn,
+			DeadlineDelay: 10 * time.Second,
+		}
+	}
+
+	conn, err := pgx.ConnectConfig(context.Background(), pgCfg)
 	if err != nil {
 		return fmt.Errorf("could not connect to database: %w", err)
 	}
@@ -65,65 +105,502 @@ func (p *Postgres) GetTables() ([]string, error) {
 	return tables, nil
 }
 
-type Column struct {
-	Name string
-	Type uint32
+func (p *Postgres) Run(ctx context.Context, query string, args ...any) (*QueryResult, error) {
+	// Describe the statement first, as the catalog cannot be queried for
+	// column metadata once the rows start arriving.
+	sd, err := p.conn.Prepare(ctx, "", query)
+	if err != nil {
+		return nil, newQueryError(query, err)
+	}
+
+	columns, err := p.describeColumns(ctx, sd.Fields)
+	if err != nil {
+		return nil, newQueryError(query, err)
+	}
+
+	// Statements that return no rows are executed for the number of rows
+	// they change, which pgx only reports once the rows are read.
+	if len(sd.Fields) == 0 {
+		tag, err := p.conn.Exec(ctx, query, args...)
+		if err != nil {
+			return nil, newQueryError(query, err)
+		}
+
+		command, _, _ := strings.Cut(tag.String(), " ")
+		return NewExecResult(command, tag.RowsAffected()), nil
+	}
+
+	source, err := p.describeSource(ctx, sd.Fields, columns)
+	if err != nil {
+		return nil, newQueryError(query, err)
+	}
+
+	rows, err := p.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, newQueryError(query, err)
+	}
+
+	result := NewQueryResult(columns, &pgRowSource{query: query, rows: rows})
+	result.Source = source
+
+	return result, nil
 }
 
-type Result struct {
-	Columns []Column
-	Rows    []map[string]any
+// ExecTx implements DatabaseIntegration.
+func (p *Postgres) ExecTx(ctx context.Context, statements []Statement) error {
+	// Beginning a transaction inside another one creates a savepoint.
+	var db interface {
+		Begin(context.Context) (pgx.Tx, error)
+	} = p.conn
+	if p.tx != nil {
+		db = p.tx
+	}
+
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		for _, stmt := range statements {
+			_, err := tx.Exec(ctx, stmt.SQL, stmt.Args...)
+			if err != nil {
+				return newQueryError(stmt.Preview, err)
+			}
+		}
+
+		return nil
+	})
 }
 
-func (p *Postgres) Run(query string) (*QueryResult, error) {
-	rows, err := p.conn.Query(context.Background(), query)
+// Begin implements DatabaseIntegration.
+func (p *Postgres) Begin(ctx context.Context) error {
+	if p.tx != nil {
+		return errors.New("a transaction is already open")
+	}
+
+	tx, err := p.conn.Begin(ctx)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("could not begin transaction: %w", err)
 	}
-	defer rows.Close()
 
-	columns := rows.FieldDescriptions()
+	p.tx = tx
+
+	return nil
+}
+
+// Commit implements DatabaseIntegration.
+func (p *Postgres) Commit(ctx context.Context) error {
+	if p.tx == nil {
+		return errors.New("no transaction is open")
+	}
+
+	// The transaction is over even if committing fails, as Postgres rolls
+	// back transactions that cannot be committed.
+	err := p.tx.Commit(ctx)
+	p.tx = nil
+	if err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Rollback implements DatabaseIntegration.
+func (p *Postgres) Rollback(ctx context.Context) error {
+	if p.tx == nil {
+		return errors.New("no transaction is open")
+	}
+
+	err := p.tx.Rollback(ctx)
+	p.tx = nil
+	if err != nil {
+		return fmt.Errorf("could not roll back transaction: %w", err)
+	}
+
+	return nil
+}
+
+// EstimateRows implements DatabaseIntegration.
+func (p *Postgres) EstimateRows(ctx context.Context, query string) (int64, error) {
+	var db interface {
+		QueryRow(context.Context, string, ...any) pgx.Row
+	} = p.conn
+
+	// A failed statement aborts the open transaction, so the query is
+	// explained in a savepoint that is rolled back.
+	if p.tx != nil {
+		savepoint, err := p.tx.Begin(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("could not explain query: %w", err)
+		}
+		defer savepoint.Rollback(ctx)
+
+		db = savepoint
+	}
+
+	var plan []byte
+	err := db.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+query).Scan(&plan)
+	if err != nil {
+		return 0, fmt.Errorf("could not explain query: %w", err)
+	}
+
+	var plans []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		}
+	}
+	err = json.Unmarshal(plan, &plans)
+	if err != nil || len(plans) == 0 {
+		return 0, fmt.Errorf("could not read query plan: %w", err)
+	}
+
+	return int64(plans[0].Plan.Rows), nil
+}
+
+// Catalog implements DatabaseIntegration. Functions of the system catalogs
+// are left out, as there are thousands of them.
+func (p *Postgres) Catalog(ctx context.Context) (*Catalog, error) {
+	return loadCatalog(ctx, catalogQueries{
+		schemas: `
+			SELECT nspname FROM pg_namespace
+			WHERE nspname NOT LIKE 'pg\_%' AND nspname <> 'information_schema'
+			ORDER BY nspname`,
+		columns: `
+			SELECT table_schema, table_name, column_name FROM information_schema.columns
+			WHERE table_schema NOT IN ('pg_catalog', 'information_schema')
+			ORDER BY table_schema, table_name, ordinal_position`,
+		functions: `
+			SELECT DISTINCT routine_name FROM information_schema.routines
+			WHERE routine_schema NOT IN ('pg_catalog', 'information_schema')
+			ORDER BY routine_name`,
+	}, p.scanRows)
+}
+
+// Objects implements DatabaseIntegration. Only the connection's database is
+// listed, as the objects of the others cannot be read from it.
+func (p *Postgres) Objects(ctx context.Context, path []Object) ([]Object, error) {
+	switch len(path) {
+	case 0:
+		return loadObjects(ctx, p.scanRows, nil, "SELECT 'database', current_database(), ''")
+
+	case 1:
+		// The system schemas are listed after the user's.
+		return loadObjects(ctx, p.scanRows, nil, `
+			SELECT 'schema', nspname, '' FROM pg_namespace
+			WHERE nspname <> 'pg_toast' AND nspname NOT LIKE 'pg\_temp\_%' AND nspname NOT LIKE 'pg\_toast\_temp\_%'
+			ORDER BY nspname LIKE 'pg\_%' OR nspname = 'information_schema', nspname`)
+
+	case 2:
+		return loadObjects(ctx, p.scanRows, []any{path[1].Name}, `
+			SELECT CASE c.relkind WHEN 'v' THEN 'view' WHEN 'm' THEN 'materialized view' WHEN 'S' THEN 'sequence' ELSE 'table' END,
+				c.relname,
+				CASE c.relkind WHEN 'p' THEN 'partitioned' WHEN 'f' THEN 'foreign' ELSE '' END
+			FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'f', 'v', 'm', 'S')
+			ORDER BY c.relname`, `
+			SELECT 'function', p.proname, '(' || pg_get_function_identity_arguments(p.oid) || ')'
+			FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname = $1
+			ORDER BY p.proname, 3`)
 
-	result := &QueryResult{
-		Columns: make([]string, len(columns)),
-		Rows:    make([]map[string]any, 0),
+	case 3:
+		// Not-null constraints are part of the columns' details.
+		return loadObjects(ctx, p.scanRows, []any{path[1].Name, path[2].Name}, `
+			SELECT 'column', a.attname,
+				format_type(a.atttypid, a.atttypmod) || CASE WHEN a.attnotnull THEN ' not null' ELSE '' END
+			FROM pg_attribute a
+				JOIN pg_class c ON c.oid = a.attrelid
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2 AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY a.attnum`, `
+			SELECT 'index', i.relname,
+				CASE WHEN x.indisprimary THEN 'primary' WHEN x.indisunique THEN 'unique' ELSE '' END
+			FROM pg_index x
+				JOIN pg_class i ON i.oid = x.indexrelid
+				JOIN pg_class c ON c.oid = x.indrelid
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2
+			ORDER BY i.relname`, `
+			SELECT 'constraint', k.conname,
+				CASE k.contype WHEN 'p' THEN 'primary key' WHEN 'f' THEN 'foreign key' WHEN 'u' THEN 'unique'
+					WHEN 'c' THEN 'check' WHEN 'x' THEN 'exclusion' WHEN 't' THEN 'trigger' ELSE '' END
+			FROM pg_constraint k
+				JOIN pg_class c ON c.oid = k.conrelid
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2 AND k.contype <> 'n'
+			ORDER BY k.conname`)
 	}
 
-	for i, col := range columns {
-		result.Columns[i] = col.Name
+	return nil, nil
+}
+
+// Structure implements DatabaseIntegration. An empty schema means the first
+// schema of the search path.
+func (p *Postgres) Structure(ctx context.Context, schema, table string) (*TableStructure, error) {
+	var oid uint32
+	err := p.conn.QueryRow(ctx, `
+		SELECT c.oid FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relname = $2`, schema, table).Scan(&oid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("could not find table %q", table)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find table: %w", err)
+	}
+
+	// The referential actions are named the way the other databases name
+	// them.
+	const action = `CASE %s WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END`
+
+	return loadStructure(ctx, structureQueries{
+		columns: `
+			SELECT a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull,
+				CASE
+					WHEN a.attidentity = 'a' THEN 'generated always as identity'
+					WHEN a.attidentity = 'd' THEN 'generated by default as identity'
+					WHEN a.attgenerated = 's' THEN 'generated always as (' || pg_get_expr(d.adbin, d.adrelid) || ') stored'
+					ELSE COALESCE(pg_get_expr(d.adbin, d.adrelid), '')
+				END,
+				COALESCE(col_description(a.attrelid, a.attnum), '')
+			FROM pg_attribute a
+				LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+			WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY a.attnum`,
+		primaryKey: `
+			SELECT a.attname
+			FROM pg_constraint k
+				CROSS JOIN LATERAL unnest(k.conkey) WITH ORDINALITY AS u(attnum, i)
+				JOIN pg_attribute a ON a.attrelid = k.conrelid AND a.attnum = u.attnum
+			WHERE k.conrelid = $1 AND k.contype = 'p'
+			ORDER BY u.i`,
+		foreignKeys: `
+			SELECT k.oid::text, k.conname, a.attname, fn.nspname, fc.relname, fa.attname,
+				` + fmt.Sprintf(action, "k.confupdtype") + `,
+				` + fmt.Sprintf(action, "k.confdeltype") + `
+			FROM pg_constraint k
+				CROSS JOIN LATERAL unnest(k.conkey, k.confkey) WITH ORDINALITY AS u(attnum, fattnum, i)
+				JOIN pg_attribute a ON a.attrelid = k.conrelid AND a.attnum = u.attnum
+				JOIN pg_attribute fa ON fa.attrelid = k.confrelid AND fa.attnum = u.fattnum
+				JOIN pg_class fc ON fc.oid = k.confrelid
+				JOIN pg_namespace fn ON fn.oid = fc.relnamespace
+			WHERE k.conrelid = $1 AND k.contype = 'f'
+			ORDER BY k.conname, k.oid, u.i`,
+		indexes: `
+			SELECT i.relname, x.indisunique, x.indisprimary, pg_get_indexdef(x.indexrelid)
+			FROM pg_index x JOIN pg_class i ON i.oid = x.indexrelid
+			WHERE x.indrelid = $1
+			ORDER BY i.relname`,
+		checks: `
+			SELECT conname, pg_get_constraintdef(oid) FROM pg_constraint
+			WHERE conrelid = $1 AND contype = 'c'
+			ORDER BY conname`,
+		triggers: `
+			SELECT tgname, pg_get_triggerdef(oid) FROM pg_trigger
+			WHERE tgrelid = $1 AND NOT tgisinternal
+			ORDER BY tgname`,
+	}, []any{oid}, p.scanRows)
+}
+
+// scanRows implements scanRowsFunc.
+func (p *Postgres) scanRows(ctx context.Context, query string, args []any, fn func(scan func(...any) error) error) error {
+	rows, err := p.conn.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
 	for rows.Next() {
-		rowValues, err := rows.Values()
+		err = fn(rows.Scan)
 		if err != nil {
-			return nil, err
+			return err
 		}
+	}
 
-		row := make(map[string]any)
+	return rows.Err()
+}
 
-		for i, col := range columns {
-			if rowValues[i] == nil {
-				row[col.Name] = nil
-				continue
-			}
+// Dialect implements DatabaseIntegration.
+func (p *Postgres) Dialect() Dialect {
+	return DialectPostgres
+}
+
+// describeSource looks up the table a statement's result columns are read
+// from, if they are all read from the same table.
+func (p *Postgres) describeSource(ctx context.Context, fields []pgconn.FieldDescription, columns []Column) (*TableSource, error) {
+	var relation uint32
+	for _, f := range fields {
+		switch {
+		case f.TableOID == 0:
+			continue
+		case relation != 0 && f.TableOID != relation:
+			return nil, nil
+		}
+
+		relation = f.TableOID
+	}
+
+	if relation == 0 {
+		return nil, nil
+	}
+
+	// Only plain and partitioned tables can be updated, not views.
+	rows, err := p.conn.Query(ctx, `
+		SELECT n.nspname, c.relname, a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull,
+			COALESCE(a.attnum = ANY(i.indkey), false),
+			COALESCE(pg_get_expr(d.adbin, d.adrelid), CASE WHEN a.attidentity <> '' THEN 'identity' ELSE '' END),
+			a.attgenerated <> '' OR a.attidentity = 'a'
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_index i ON i.indrelid = c.oid AND i.indisprimary
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped AND c.relkind IN ('r', 'p')
+		ORDER BY a.attnum`, relation)
+	if err != nil {
+		return nil, fmt.Errorf("could not get table columns: %w", err)
+	}
+
+	var schema, table string
+	var tableColumns []TableColumn
+	var col TableColumn
+	_, err = pgx.ForEachRow(rows, []any{&schema, &table, &col.Name, &col.TypeName, &col.Nullable, &col.PrimaryKey, &col.Default, &col.Generated}, func() error {
+		tableColumns = append(tableColumns, col)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get table columns: %w", err)
+	}
+
+	if len(tableColumns) == 0 {
+		return nil, nil
+	}
+
+	return newTableSource(schema, table, columns, tableColumns), nil
+}
+
+// describeColumns looks up the type names, nullability and tables of origin
+// of a statement's result columns.
+func (p *Postgres) describeColumns(ctx context.Context, fields []pgconn.FieldDescription) ([]Column, error) {
+	type attribute struct {
+		relation uint32
+		number   uint16
+	}
+
+	var unknownTypes, relations []uint32
+	for _, f := range fields {
+		if _, ok := p.typeNames[f.DataTypeOID]; !ok && !slices.Contains(unknownTypes, f.DataTypeOID) {
+			unknownTypes = append(unknownTypes, f.DataTypeOID)
+		}
 
-			// Switch case for types that need special handling
-			switch v := rowValues[i].(type) {
-			case time.Time:
-				row[col.Name] = v.Format(time.RFC3339)
-			case [16]uint8:
-				row[col.Name] = uuid.UUID(v).String()
-			default:
-				row[col.Name] = v
+		if f.TableOID != 0 && !slices.Contains(relations, f.TableOID) {
+			relations = append(relations, f.TableOID)
+		}
+	}
+
+	if len(unknownTypes) > 0 {
+		rows, err := p.conn.Query(ctx, "SELECT oid, format_type(oid, NULL) FROM pg_type WHERE oid = ANY($1)", unknownTypes)
+		if err != nil {
+			return nil, fmt.Errorf("could not get column types: %w", err)
+		}
+
+		var oid uint32
+		var name string
+		_, err = pgx.ForEachRow(rows, []any{&oid, &name}, func() error {
+			p.typeNames[oid] = name
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get column types: %w", err)
+		}
+	}
+
+	type origin struct {
+		schema  string
+		table   string
+		name    string
+		notNull bool
+	}
+
+	origins := make(map[attribute]origin)
+
+	if len(relations) > 0 {
+		rows, err := p.conn.Query(ctx, `
+			SELECT a.attrelid, a.attnum, n.nspname, c.relname, a.attname, a.attnotnull
+			FROM pg_attribute a
+			JOIN pg_class c ON c.oid = a.attrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE a.attrelid = ANY($1) AND a.attnum > 0`, relations)
+		if err != nil {
+			return nil, fmt.Errorf("could not get column origins: %w", err)
+		}
+
+		var attr attribute
+		var o origin
+		_, err = pgx.ForEachRow(rows, []any{&attr.relation, &attr.number, &o.schema, &o.table, &o.name, &o.notNull}, func() error {
+			origins[attr] = o
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get column origins: %w", err)
+		}
+	}
+
+	columns := make([]Column, len(fields))
+	for i, f := range fields {
+		columns[i] = Column{
+			Name:     f.Name,
+			TypeOID:  f.DataTypeOID,
+			TypeName: p.typeNames[f.DataTypeOID],
+		}
+
+		if o, ok := origins[attribute{relation: f.TableOID, number: f.TableAttributeNumber}]; ok {
+			columns[i].Schema = o.schema
+			columns[i].Table = o.table
+			columns[i].TableColumn = o.name
+			columns[i].Nullable = Nullable
+			if o.notNull {
+				columns[i].Nullable = NotNull
 			}
 		}
+	}
+
+	return columns, nil
+}
+
+// pgRowSource reads rows from a pgx result set. The connection stays busy
+// until the source is closed.
+type pgRowSource struct {
+	query string
+	rows  pgx.Rows
+}
+
+func (s *pgRowSource) Next(n int) ([][]any, error) {
+	result := make([][]any, 0, n)
+
+	for len(result) < n && s.rows.Next() {
+		// Values are kept as decoded by pgx so they can be sent back to the
+		// server as they are, and are only formatted for display.
+		row, err := s.rows.Values()
+		if err != nil {
+			return nil, newQueryError(s.query, err)
+		}
+
+		result = append(result, row)
+	}
 
-		result.Rows = append(result.Rows, row)
+	// pgx reports most query errors once the rows have been read.
+	if err := s.rows.Err(); err != nil {
+		return nil, newQueryError(s.query, err)
 	}
 
 	return result, nil
 }
 
+func (s *pgRowSource) Close() error {
+	s.rows.Close()
+	return s.rows.Err()
+}
+
 func (p *Postgres) Close() error {
+	if p.conn == nil {
+		return nil
+	}
+
 	return p.conn.Close(context.Background())
 }
@@ -0,0 +1,106 @@
+package database
+
+import "strings"
+
+// TableRef is a table that a statement refers to.
+type TableRef struct {
+	// Schema is the schema the table's name is qualified with, or empty if
+	// it is not qualified.
+	Schema string
+	Name   string
+	// Alias is the name the statement gives the table, or empty if it gives
+	// it none.
+	Alias string
+}
+
+var (
+	// referenceKeywords are followed by the name of a table, or by a list of
+	// them after FROM.
+	referenceKeywords = []string{"FROM", "JOIN", "UPDATE", "INTO"}
+	// clauseKeywords may follow a table's name, so they are never its alias.
+	clauseKeywords = []string{
+		"WHERE", "JOIN", "INNER", "LEFT", "RIGHT", "FULL", "OUTER", "CROSS", "NATURAL",
+		"STRAIGHT_JOIN", "ON", "USING", "GROUP", "ORDER", "HAVING", "WINDOW", "LIMIT",
+		"OFFSET", "FETCH", "FOR", "UNION", "EXCEPT", "INTERSECT", "SET", "VALUES",
+		"SELECT", "DEFAULT", "RETURNING", "TABLESAMPLE", "USE", "FORCE", "IGNORE",
+		"PARTITION", "WITH", "LATERAL",
+	}
+)
+
+// References returns the tables that query reads from or writes to: those
+// named after FROM, JOIN, UPDATE and INTO, with their aliases. Names are
+// unquoted. The query may be incomplete, as it is while it is typed.
+func (d Dialect) References(query string) []TableRef {
+	tokens := d.lex(query)
+
+	var refs []TableRef
+	for i, t := range tokens {
+		if !containsKeyword(referenceKeywords, t) {
+			continue
+		}
+
+		for j := i + 1; ; j++ {
+			ref, next, ok := tableRef(tokens, j)
+			if !ok {
+				break
+			}
+			refs = append(refs, ref)
+
+			// FROM may be followed by several tables separated by commas.
+			j = next
+			if !t.is("FROM") || j >= len(tokens) || tokens[j].kind != tokenPunct || tokens[j].text != "," {
+				break
+			}
+		}
+	}
+
+	return refs
+}
+
+// tableRef reads the table name starting at tokens[i], and the alias that
+// follows it, if any. It returns the index of the token after them.
+func tableRef(tokens []token, i int) (TableRef, int, bool) {
+	for i < len(tokens) && tokens[i].is("ONLY") {
+		i++
+	}
+
+	var parts []string
+	for i < len(tokens) && isNamePart(tokens[i], true) && !containsKeyword(clauseKeywords, tokens[i]) {
+		parts = append(parts, unquoteIdentifier(tokens[i].text))
+		i++
+
+		if i+1 >= len(tokens) || !isNamePart(tokens[i], false) {
+			break
+		}
+		i++
+	}
+	if len(parts) == 0 {
+		return TableRef{}, i, false
+	}
+
+	ref := TableRef{Name: parts[len(parts)-1]}
+	if len(parts) > 1 {
+		ref.Schema = parts[len(parts)-2]
+	}
+
+	if i < len(tokens) && tokens[i].is("AS") {
+		i++
+	}
+	if i < len(tokens) && isNamePart(tokens[i], true) && !containsKeyword(clauseKeywords, tokens[i]) {
+		ref.Alias = unquoteIdentifier(tokens[i].text)
+		i++
+	}
+
+	return ref, i, true
+}
+
+// unquoteIdentifier returns the name of a quoted identifier, or name as is if
+// it is not quoted.
+func unquoteIdentifier(name string) string {
+	if len(name) < 2 || name[0] != '"' && name[0] != '`' {
+		return name
+	}
+
+	quote := name[:1]
+	return strings.ReplaceAll(strings.TrimSuffix(name[1:], quote), quote+quote, quote)
+}
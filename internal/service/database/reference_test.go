@@ -0,0 +1,43 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    []TableRef
+	}{
+		{DialectPostgres, "SELECT * FROM t", []TableRef{{Name: "t"}}},
+		{DialectPostgres, "SELECT * FROM public.t AS x WHERE x.a = 1", []TableRef{{Schema: "public", Name: "t", Alias: "x"}}},
+		{DialectPostgres, `SELECT * FROM "My Schema"."My Table" m`, []TableRef{{Schema: "My Schema", Name: "My Table", Alias: "m"}}},
+		{DialectPostgres, "SELECT * FROM a, b x, c", []TableRef{{Name: "a"}, {Name: "b", Alias: "x"}, {Name: "c"}}},
+		{DialectPostgres, "SELECT * FROM a JOIN b ON a.id = b.id LEFT JOIN c USING (id)", []TableRef{{Name: "a"}, {Name: "b"}, {Name: "c"}}},
+		{DialectPostgres, "SELECT * FROM ONLY t WHERE", []TableRef{{Name: "t"}}},
+		{DialectPostgres, "UPDATE t SET a = 1", []TableRef{{Name: "t"}}},
+		{DialectPostgres, "INSERT INTO t (a) SELECT a FROM u", []TableRef{{Name: "t"}, {Name: "u"}}},
+		{DialectPostgres, "SELECT * FROM (SELECT * FROM t) s", []TableRef{{Name: "t"}}},
+		{DialectMySQL, "SELECT * FROM `db`.`t` AS `x`", []TableRef{{Schema: "db", Name: "t", Alias: "x"}}},
+
+		// Incomplete queries, as they are while typed.
+		{DialectPostgres, "SELECT * FROM t x WHERE x.", []TableRef{{Name: "t", Alias: "x"}}},
+		{DialectPostgres, "SELECT * FROM public.", []TableRef{{Name: "public"}}},
+		{DialectPostgres, "SELECT * FROM", nil},
+
+		// Table names in strings and comments are not references.
+		{DialectPostgres, "SELECT 'FROM t' FROM u -- JOIN v", []TableRef{{Name: "u"}}},
+		{DialectPostgres, "SELECT $$ FROM t $$", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := tt.dialect.References(tt.query)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
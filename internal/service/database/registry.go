@@ -0,0 +1,67 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Factory creates a new, unconnected DatabaseIntegration.
+type Factory func() DatabaseIntegration
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a driver available under the given type name, which is
+// matched against ConnectionConfig.Type. It panics if the name is empty or
+// already registered.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name = strings.ToLower(name)
+	if name == "" {
+		panic("database: Register driver name is empty")
+	}
+
+	if factory == nil {
+		panic("database: Register factory is nil for driver " + name)
+	}
+
+	if _, exists := registry[name]; exists {
+		panic("database: Register called twice for driver " + name)
+	}
+
+	registry[name] = factory
+}
+
+// New creates a driver for the given connection type.
+func New(connType string) (DatabaseIntegration, error) {
+	registryMu.RLock()
+	factory, ok := registry[strings.ToLower(connType)]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown database type %q (available: %s)", connType, strings.Join(Drivers(), ", "))
+	}
+
+	return factory(), nil
+}
+
+// Drivers returns the sorted names of the registered drivers.
+func Drivers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
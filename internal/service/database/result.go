@@ -0,0 +1,235 @@
+package database
+
+import "errors"
+
+// Nullability reports whether a result column can hold NULL.
+type Nullability int
+
+const (
+	// NullabilityUnknown is used for columns computed by an expression or
+	// when the driver cannot tell.
+	NullabilityUnknown Nullability = iota
+	Nullable
+	NotNull
+)
+
+// Column describes a column of a result set.
+type Column struct {
+	Name string
+	// TypeOID is the database's identifier for the column type, for
+	// databases that have one.
+	TypeOID  uint32
+	TypeName string
+	Nullable Nullability
+	// Schema and Table name the table the column originates from, if any,
+	// and TableColumn its name in that table, which differs from Name when
+	// the column is aliased.
+	Schema      string
+	Table       string
+	TableColumn string
+}
+
+// TableColumn describes a column of a table.
+type TableColumn struct {
+	Name       string
+	TypeName   string
+	Nullable   bool
+	PrimaryKey bool
+	// Default is the expression of the column's default value, or a
+	// description of how it is generated, e.g. "auto_increment". It is empty
+	// if the column has no default.
+	Default string
+	// Generated reports whether the column's value is always computed by the
+	// database, so it cannot be inserted.
+	Generated bool
+}
+
+// TableSource is the table a result set was read from, for result sets whose
+// rows can be edited: every column read from a table comes from this one, and
+// the table's whole primary key is among them.
+type TableSource struct {
+	Schema  string
+	Table   string
+	Columns []TableColumn
+}
+
+// PrimaryKey returns the names of the table's primary key columns.
+func (t *TableSource) PrimaryKey() []string {
+	var key []string
+	for _, col := range t.Columns {
+		if col.PrimaryKey {
+			key = append(key, col.Name)
+		}
+	}
+
+	return key
+}
+
+// newTableSource returns the source of a result set with the given columns,
+// or nil if its rows cannot be edited.
+func newTableSource(schema, table string, resultColumns []Column, tableColumns []TableColumn) *TableSource {
+	source := &TableSource{Schema: schema, Table: table, Columns: tableColumns}
+
+	key := source.PrimaryKey()
+	if len(key) == 0 {
+		return nil
+	}
+
+	for _, name := range key {
+		if ColumnIndex(resultColumns, source, name) < 0 {
+			return nil
+		}
+	}
+
+	return source
+}
+
+// ColumnIndex returns the index of the result column read from the named
+// column of source, or -1 if the result set does not include it.
+func ColumnIndex(columns []Column, source *TableSource, name string) int {
+	for i, col := range columns {
+		if col.Schema == source.Schema && col.Table == source.Table && col.TableColumn == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// RowSource yields the rows of an open result set.
+type RowSource interface {
+	// Next reads up to n more rows. It returns fewer than n rows only once
+	// the result set is exhausted.
+	Next(n int) ([][]any, error)
+	// Close releases the result set. It is safe to call more than once.
+	Close() error
+}
+
+// QueryResult is a result set whose rows are fetched in batches as they are
+// needed, so that large results do not have to be held in memory at once.
+type QueryResult struct {
+	Columns []Column
+	// Rows holds the rows fetched so far. Values are positional, in the
+	// order of Columns.
+	Rows [][]any
+	// HasMore reports whether more rows can be fetched.
+	HasMore bool
+	// Capped reports whether fetching stopped because MaxRows was reached
+	// while the result set still had rows left.
+	Capped bool
+	// Source is the table the rows were read from, if they can be edited.
+	Source *TableSource
+	// Command is the statement that ran, e.g. "UPDATE", and RowsAffected the
+	// number of rows it changed, for statements that return no rows. It is
+	// -1 for statements that do.
+	Command      string
+	RowsAffected int64
+
+	source  RowSource
+	pending [][]any
+	maxRows int
+}
+
+// NewQueryResult creates a result set that reads its rows from source. No
+// rows are read until Fetch is called.
+func NewQueryResult(columns []Column, source RowSource) *QueryResult {
+	return &QueryResult{
+		Columns:      columns,
+		Rows:         make([][]any, 0),
+		HasMore:      true,
+		RowsAffected: -1,
+		source:       source,
+	}
+}
+
+// NewExecResult creates the result of a statement that returns no rows but
+// changed rowsAffected rows.
+func NewExecResult(command string, rowsAffected int64) *QueryResult {
+	result := NewQueryResult(nil, &sliceRowSource{})
+	result.Command = command
+	result.RowsAffected = rowsAffected
+
+	return result
+}
+
+// SetMaxRows caps the total number of rows that will be fetched. Zero or less
+// disables the cap.
+func (r *QueryResult) SetMaxRows(n int) {
+	r.maxRows = n
+}
+
+// Fetch reads up to n more rows into Rows. The source is closed once it is
+// exhausted, the row cap is reached, or reading fails.
+func (r *QueryResult) Fetch(n int) error {
+	if !r.HasMore {
+		return nil
+	}
+
+	if r.maxRows > 0 {
+		n = min(n, r.maxRows-len(r.Rows))
+	}
+
+	// Read one row past the batch to know whether more are available.
+	rows := r.pending
+	r.pending = nil
+
+	if want := n + 1 - len(rows); want > 0 {
+		batch, err := r.source.Next(want)
+		if err != nil {
+			r.HasMore = false
+			return errors.Join(err, r.source.Close())
+		}
+
+		rows = append(rows, batch...)
+	}
+
+	if len(rows) > n {
+		r.pending = rows[n:]
+		rows = rows[:n]
+	}
+
+	r.Rows = append(r.Rows, rows...)
+
+	r.HasMore = len(r.pending) > 0
+	if r.HasMore && r.maxRows > 0 && len(r.Rows) >= r.maxRows {
+		r.HasMore = false
+		r.Capped = true
+	}
+
+	if !r.HasMore {
+		r.pending = nil
+		return r.source.Close()
+	}
+
+	return nil
+}
+
+// Close releases the underlying result set, discarding any unread rows.
+func (r *QueryResult) Close() error {
+	r.HasMore = false
+	r.pending = nil
+
+	if r.source == nil {
+		return nil
+	}
+
+	return r.source.Close()
+}
+
+// sliceRowSource serves rows that are already held in memory.
+type sliceRowSource struct {
+	rows [][]any
+}
+
+func (s *sliceRowSource) Next(n int) ([][]any, error) {
+	n = min(n, len(s.rows))
+	rows := s.rows[:n]
+	s.rows = s.rows[n:]
+
+	return rows, nil
+}
+
+func (s *sliceRowSource) Close() error {
+	s.rows = nil
+	return nil
+}
@@ -1,16 +1,50 @@
 package database
 
-import "github.com/davesavic/lazydb/internal/service/config"
+import (
+	"context"
+	"errors"
 
-type QueryResult struct {
-	Columns []string
-	Rows    []map[string]any
-}
+	"github.com/davesavic/lazydb/internal/service/config"
+)
 
 type DatabaseIntegration interface {
 	Name() string
 	Connect(config.ConnectionConfig) error
 	GetTables() ([]string, error)
-	Run(query string) (*QueryResult, error)
+	// Run executes query with args bound to its placeholders and returns
+	// its result set before any rows are read. ctx must stay alive until the
+	// result is closed; cancelling it stops the query without closing the
+	// connection.
+	Run(ctx context.Context, query string, args ...any) (*QueryResult, error)
+	// ExecTx executes statements in a single transaction, which is rolled
+	// back if any of them fails. Inside a transaction opened by Begin, the
+	// statements are rolled back to a savepoint instead.
+	ExecTx(ctx context.Context, statements []Statement) error
+	// Begin opens a transaction that every following statement runs in
+	// until Commit or Rollback is called.
+	Begin(ctx context.Context) error
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+	// EstimateRows estimates how many rows query returns without running it,
+	// using the planner's statistics. It returns ErrNotSupported if the
+	// database keeps none.
+	EstimateRows(ctx context.Context, query string) (int64, error)
+	// Catalog lists the schemas, tables, columns and functions that queries
+	// can refer to.
+	Catalog(ctx context.Context) (*Catalog, error)
+	// Objects lists the objects inside the object at path, which starts
+	// with one of the objects listed for an empty path: the databases or
+	// schemas, the objects of a schema, and the columns, indexes and
+	// constraints of a table or view. Only containers have objects inside.
+	Objects(ctx context.Context, path []Object) ([]Object, error)
+	// Structure describes the columns, keys, indexes, check constraints and
+	// triggers of a table or view. An empty schema means the one tables are
+	// looked up in by default.
+	Structure(ctx context.Context, schema, table string) (*TableStructure, error)
+	// Dialect is the SQL dialect statements for the database are written in.
+	Dialect() Dialect
 	Close() error
 }
+
+// ErrNotSupported is returned by drivers for operations they cannot perform.
+var ErrNotSupported = errors.New("not supported by this database")
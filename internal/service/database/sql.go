@@ -0,0 +1,500 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// initConnector runs a statement on every connection it opens, to set up
+// connection settings that database/sql would otherwise lose when it opens
+// another connection for its pool.
+type initConnector struct {
+	driver.Connector
+	query string
+}
+
+func (c *initConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		_ = conn.Close()
+		return nil, fmt.Errorf("could not set up connection: %w", ErrNotSupported)
+	}
+
+	_, err = execer.ExecContext(ctx, c.query, nil)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("could not set up connection: %w", err)
+	}
+
+	return conn, nil
+}
+
+// newSQLQueryResult wraps a database/sql result set in a QueryResult. The
+// convert function maps each scanned value into a displayable Go value based
+// on the column's database type name.
+func newSQLQueryResult(query string, rows *sql.Rows, convert func(typeName string, value any) any) (*QueryResult, error) {
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		return nil, newQueryError(query, err)
+	}
+
+	return NewQueryResult(sqlColumns(columns), &sqlRowSource{
+		query:   query,
+		rows:    rows,
+		columns: columns,
+		convert: convert,
+	}), nil
+}
+
+// sqlColumns describes the columns of a database/sql result set. The driver
+// interface does not expose which table a column comes from.
+func sqlColumns(columnTypes []*sql.ColumnType) []Column {
+	columns := make([]Column, len(columnTypes))
+	for i, ct := range columnTypes {
+		columns[i] = Column{
+			Name:     ct.Name(),
+			TypeName: ct.DatabaseTypeName(),
+		}
+
+		if nullable, ok := ct.Nullable(); ok {
+			columns[i].Nullable = NotNull
+			if nullable {
+				columns[i].Nullable = Nullable
+			}
+		}
+	}
+
+	return columns
+}
+
+// sqlTable is the table a plain SELECT from a single table reads from, as
+// database/sql does not report which table result columns come from.
+type sqlTable struct {
+	schema string
+	table  string
+	// items are the names of the selected columns, in order, with "*" for
+	// all of the table's columns.
+	items   []string
+	columns []TableColumn
+}
+
+// selectClauses are the clauses a plain SELECT may have after its table,
+// which filter and order its rows but do not change where they come from.
+var selectClauses = []string{"WHERE", "ORDER", "LIMIT", "OFFSET"}
+
+// selectFrom parses query if it is a plain SELECT of columns from a single
+// table: every selected item is *, or a column of the table that is neither
+// aliased nor part of an expression, and there are no joins, subqueries,
+// set operations or grouping. The table's columns are left to be looked up.
+func (d Dialect) selectFrom(query string) (*sqlTable, bool) {
+	tokens := d.lex(query)
+	if len(tokens) > 0 && tokens[len(tokens)-1].text == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	for i, tok := range tokens {
+		switch {
+		case tok.is("SELECT") && i > 0,
+			tok.depth == 0 && (tok.is("UNION") || tok.is("INTERSECT") || tok.is("EXCEPT") || tok.is("MINUS") ||
+				tok.is("JOIN") || tok.is("GROUP") || tok.is("HAVING") || tok.is("WINDOW") || tok.is("INTO")),
+			tok.text == ";":
+			return nil, false
+		}
+	}
+
+	if len(tokens) == 0 || !tokens[0].is("SELECT") {
+		return nil, false
+	}
+
+	from := slices.IndexFunc(tokens, func(tok token) bool { return tok.depth == 0 && tok.is("FROM") })
+	if from < 0 {
+		return nil, false
+	}
+
+	// FROM [schema.]table [[AS] alias], then nothing or one of the clauses.
+	names, i := d.qualifiedName(tokens, from+1)
+	if len(names) == 0 || len(names) > 2 {
+		return nil, false
+	}
+
+	t := &sqlTable{table: names[len(names)-1]}
+	if len(names) == 2 {
+		t.schema = names[0]
+	}
+
+	var alias string
+	if i < len(tokens) && tokens[i].is("AS") {
+		i++
+	}
+	if i < len(tokens) && d.isIdentifier(tokens[i]) && !slices.ContainsFunc(selectClauses, tokens[i].is) {
+		alias = unquoteIdentifier(tokens[i].text)
+		i++
+	}
+	if i < len(tokens) && !slices.ContainsFunc(selectClauses, tokens[i].is) {
+		return nil, false
+	}
+
+	list := tokens[1:from]
+	if len(list) > 0 && list[0].is("ALL") {
+		list = list[1:]
+	}
+
+	for {
+		end := slices.IndexFunc(list, func(tok token) bool { return tok.text == "," })
+		if end < 0 {
+			end = len(list)
+		}
+
+		item, ok := d.selectItem(list[:end], t, alias)
+		if !ok {
+			return nil, false
+		}
+		t.items = append(t.items, item)
+
+		if end == len(list) {
+			return t, true
+		}
+		list = list[end+1:]
+	}
+}
+
+// selectItem returns the column an item of a select list reads from t, or
+// "*" for all of its columns. Columns may be qualified with the table's name,
+// or its alias if it has one.
+func (d Dialect) selectItem(item []token, t *sqlTable, alias string) (string, bool) {
+	star := len(item) > 0 && item[len(item)-1].text == "*"
+	if star {
+		item = item[:len(item)-1]
+		if len(item) > 0 && item[len(item)-1].text != "." {
+			return "", false
+		}
+		if len(item) > 0 {
+			item = item[:len(item)-1]
+		}
+	}
+
+	names, i := d.qualifiedName(item, 0)
+	if i < len(item) {
+		return "", false
+	}
+
+	if star {
+		names = append(names, "*")
+	}
+
+	var qualified bool
+	switch len(names) {
+	case 1:
+		qualified = true
+	case 2:
+		qualified = alias != "" && strings.EqualFold(names[0], alias) ||
+			alias == "" && strings.EqualFold(names[0], t.table)
+	case 3:
+		qualified = alias == "" && strings.EqualFold(names[0], t.schema) && strings.EqualFold(names[1], t.table)
+	}
+
+	if !qualified {
+		return "", false
+	}
+
+	return names[len(names)-1], true
+}
+
+// qualifiedName reads the parts of a dotted name starting at tokens[i], and
+// returns them unquoted with the index of the token after the name.
+func (d Dialect) qualifiedName(tokens []token, i int) ([]string, int) {
+	var names []string
+	for i < len(tokens) && d.isIdentifier(tokens[i]) && tokens[i].depth == 0 {
+		names = append(names, unquoteIdentifier(tokens[i].text))
+		i++
+
+		if i+1 >= len(tokens) || tokens[i].text != "." || !d.isIdentifier(tokens[i+1]) {
+			break
+		}
+		i++
+	}
+
+	return names, i
+}
+
+// isIdentifier reports whether tok is an unquoted or quoted identifier. MySQL
+// reads double quotes as a string.
+func (d Dialect) isIdentifier(tok token) bool {
+	switch tok.kind {
+	case tokenWord:
+		return true
+	case tokenQuoted:
+		return tok.text[0] == '`' || tok.text[0] == '"' && d != DialectMySQL
+	}
+
+	return false
+}
+
+// lookupSQLTable finds the table query reads from when it is a plain SELECT
+// of columns from a single table, or returns nil. lookup returns the columns
+// of a table, and no columns if there is no such table. The table is looked
+// up before the query is run, as the session's connection is busy until its
+// rows are read.
+func lookupSQLTable(ctx context.Context, d Dialect, query string, lookup func(ctx context.Context, schema, table string) ([]TableColumn, error)) *sqlTable {
+	t, ok := d.selectFrom(query)
+	if !ok {
+		return nil
+	}
+
+	tableColumns, err := lookup(ctx, t.schema, t.table)
+	if err != nil || len(tableColumns) == 0 {
+		// The result set can still be shown, it just cannot be edited.
+		return nil
+	}
+
+	t.columns = tableColumns
+
+	return t
+}
+
+// source describes the result columns read from t, which are its selected
+// items in order with * standing for all of the table's columns.
+func (t *sqlTable) source(columns []Column) *TableSource {
+	if t == nil {
+		return nil
+	}
+
+	var names []string
+	for _, item := range t.items {
+		if item == "*" {
+			for _, tc := range t.columns {
+				names = append(names, tc.Name)
+			}
+			continue
+		}
+
+		i := slices.IndexFunc(t.columns, func(tc TableColumn) bool { return strings.EqualFold(tc.Name, item) })
+		if i < 0 {
+			return nil
+		}
+		names = append(names, t.columns[i].Name)
+	}
+
+	if len(names) != len(columns) {
+		return nil
+	}
+
+	for i, name := range names {
+		columns[i].Schema = t.schema
+		columns[i].Table = t.table
+		columns[i].TableColumn = name
+	}
+
+	return newTableSource(t.schema, t.table, columns, t.columns)
+}
+
+// sqlConn is the connection of a database/sql driver, and the transaction
+// opened by Begin, if any. Statements must be run on the transaction to be
+// part of it.
+type sqlConn struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// pin limits the pool of db to a single connection that is kept open, so that
+// session state such as variables, the current database and temporary tables
+// carries over from one statement to the next. A connection that breaks, e.g.
+// when a query on it is cancelled, is replaced by a new one.
+//
+// As the connection is busy while a result set is open or a transaction holds
+// it, every statement must go through queryContext, exec or queryRow, and only
+// once the previous result set is closed.
+func (c *sqlConn) pin(db *sql.DB) {
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	c.db = db
+}
+
+// queryContext runs a query in the open transaction, if there is one.
+func (c *sqlConn) queryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if c.tx != nil {
+		return c.tx.QueryContext(ctx, query, args...)
+	}
+
+	return c.db.QueryContext(ctx, query, args...)
+}
+
+// exec executes a statement that returns no rows, for the number of rows it
+// changes.
+func (c *sqlConn) exec(ctx context.Context, dialect Dialect, query string, args ...any) (*QueryResult, error) {
+	var result sql.Result
+	var err error
+	if c.tx != nil {
+		result, err = c.tx.ExecContext(ctx, query, args...)
+	} else {
+		result, err = c.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, newQueryError(query, err)
+	}
+
+	// Not every statement reports the rows it changes, e.g. CREATE TABLE.
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		rowsAffected = 0
+	}
+
+	return NewExecResult(dialect.command(query), rowsAffected), nil
+}
+
+func (c *sqlConn) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	if c.tx != nil {
+		return c.tx.QueryRowContext(ctx, query, args...)
+	}
+
+	return c.db.QueryRowContext(ctx, query, args...)
+}
+
+func (c *sqlConn) begin() error {
+	if c.tx != nil {
+		return errors.New("a transaction is already open")
+	}
+
+	// The transaction must outlive the context of the statement that opens
+	// it, as database/sql rolls it back once its context is done.
+	tx, err := c.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	c.tx = tx
+
+	return nil
+}
+
+func (c *sqlConn) commit() error {
+	if c.tx == nil {
+		return errors.New("no transaction is open")
+	}
+
+	err := c.tx.Commit()
+	c.tx = nil
+	if err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (c *sqlConn) rollback() error {
+	if c.tx == nil {
+		return errors.New("no transaction is open")
+	}
+
+	err := c.tx.Rollback()
+	c.tx = nil
+	if err != nil {
+		return fmt.Errorf("could not roll back transaction: %w", err)
+	}
+
+	return nil
+}
+
+// execTx executes statements in a single transaction, or inside the open
+// transaction up to a savepoint.
+func (c *sqlConn) execTx(ctx context.Context, statements []Statement) error {
+	if c.tx != nil {
+		return execSavepoint(ctx, c.tx, statements)
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range statements {
+		_, err = tx.ExecContext(ctx, stmt.SQL, stmt.Args...)
+		if err != nil {
+			return newQueryError(stmt.Preview, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// execSavepoint executes statements in tx, rolling back only these
+// statements if one of them fails.
+func execSavepoint(ctx context.Context, tx *sql.Tx, statements []Statement) error {
+	_, err := tx.ExecContext(ctx, "SAVEPOINT lazydb_changes")
+	if err != nil {
+		return fmt.Errorf("could not create savepoint: %w", err)
+	}
+
+	for _, stmt := range statements {
+		_, err = tx.ExecContext(ctx, stmt.SQL, stmt.Args...)
+		if err != nil {
+			_, _ = tx.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT lazydb_changes")
+			return newQueryError(stmt.Preview, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT lazydb_changes")
+	if err != nil {
+		return fmt.Errorf("could not release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// sqlRowSource reads rows from a database/sql result set.
+type sqlRowSource struct {
+	query   string
+	rows    *sql.Rows
+	columns []*sql.ColumnType
+	convert func(typeName string, value any) any
+}
+
+func (s *sqlRowSource) Next(n int) ([][]any, error) {
+	result := make([][]any, 0, n)
+
+	for len(result) < n && s.rows.Next() {
+		row := make([]any, len(s.columns))
+		pointers := make([]any, len(s.columns))
+		for i := range row {
+			pointers[i] = &row[i]
+		}
+
+		err := s.rows.Scan(pointers...)
+		if err != nil {
+			return nil, newQueryError(s.query, err)
+		}
+
+		for i, col := range s.columns {
+			row[i] = s.convert(col.DatabaseTypeName(), row[i])
+		}
+
+		result = append(result, row)
+	}
+
+	if err := s.rows.Err(); err != nil {
+		return nil, newQueryError(s.query, err)
+	}
+
+	return result, nil
+}
+
+func (s *sqlRowSource) Close() error {
+	return s.rows.Close()
+}
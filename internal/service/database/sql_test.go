@@ -0,0 +1,102 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSelectFrom(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		schema  string
+		table   string
+		items   []string
+	}{
+		{DialectSQLite, "SELECT * FROM people", "", "people", []string{"*"}},
+		{DialectSQLite, "select id, name from people where id > 1 order by name limit 10;", "", "people", []string{"id", "name"}},
+		{DialectSQLite, `SELECT p.id, "p".name FROM main.people AS p`, "main", "people", []string{"id", "name"}},
+		{DialectSQLite, "SELECT people.*, id FROM people", "", "people", []string{"*", "id"}},
+		{DialectSQLite, "SELECT main.people.id FROM main.people", "main", "people", []string{"id"}},
+		{DialectSQLite, "SELECT ALL id FROM people WHERE name IN ('a', 'b')", "", "people", []string{"id"}},
+		{DialectMySQL, "SELECT `id`, `full name` FROM `shop`.`people` p WHERE p.id = ?", "shop", "people", []string{"id", "full name"}},
+
+		// Aliased columns and expressions are not read as they are.
+		{DialectSQLite, "SELECT other_id AS id, name FROM people", "", "", nil},
+		{DialectSQLite, "SELECT other_id id, name FROM people", "", "", nil},
+		{DialectSQLite, "SELECT id + 1 FROM people", "", "", nil},
+		{DialectSQLite, "SELECT upper(name), id FROM people", "", "", nil},
+		{DialectSQLite, "SELECT DISTINCT id FROM people", "", "", nil},
+		{DialectSQLite, "SELECT 'id' FROM people", "", "", nil},
+		{DialectMySQL, `SELECT "id" FROM people`, "", "", nil},
+
+		// Columns must come from the selected table, by its alias if it has
+		// one.
+		{DialectSQLite, "SELECT other.id FROM people", "", "", nil},
+		{DialectSQLite, "SELECT people.id FROM people p", "", "", nil},
+		{DialectSQLite, "SELECT id, FROM people", "", "", nil},
+
+		// Rows that do not come from a single table.
+		{DialectSQLite, "SELECT id FROM people WHERE id = 1 UNION SELECT id FROM other", "", "", nil},
+		{DialectSQLite, "SELECT id FROM people EXCEPT SELECT id FROM other", "", "", nil},
+		{DialectSQLite, "SELECT id FROM people p JOIN other o ON o.id = p.id", "", "", nil},
+		{DialectSQLite, "SELECT id FROM people, other", "", "", nil},
+		{DialectSQLite, "SELECT id FROM people WHERE id IN (SELECT id FROM other)", "", "", nil},
+		{DialectSQLite, "SELECT id FROM (SELECT id FROM people)", "", "", nil},
+		{DialectSQLite, "SELECT id FROM people GROUP BY id", "", "", nil},
+		{DialectSQLite, "WITH p AS (SELECT id FROM people) SELECT id FROM p", "", "", nil},
+		{DialectSQLite, "SELECT id FROM people; DELETE FROM people", "", "", nil},
+		{DialectSQLite, "SELECT 1", "", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, ok := tt.dialect.selectFrom(tt.query)
+			if !ok {
+				if tt.items != nil {
+					t.Fatalf("not a plain SELECT, want %s.%s", tt.schema, tt.table)
+				}
+				return
+			}
+
+			if tt.items == nil {
+				t.Fatalf("got %s.%s %v, want not a plain SELECT", got.schema, got.table, got.items)
+			}
+			if got.schema != tt.schema || got.table != tt.table || !slices.Equal(got.items, tt.items) {
+				t.Errorf("got %s.%s %v, want %s.%s %v", got.schema, got.table, got.items, tt.schema, tt.table, tt.items)
+			}
+		})
+	}
+}
+
+func TestSQLTableSource(t *testing.T) {
+	table := &sqlTable{
+		table: "people",
+		items: []string{"*", "ID"},
+		columns: []TableColumn{
+			{Name: "id", PrimaryKey: true},
+			{Name: "name"},
+		},
+	}
+
+	columns := []Column{{Name: "id"}, {Name: "name"}, {Name: "id"}}
+	source := table.source(columns)
+	if source == nil {
+		t.Fatal("result is not editable")
+	}
+	for i, want := range []string{"id", "name", "id"} {
+		if columns[i].Table != "people" || columns[i].TableColumn != want {
+			t.Errorf("column %d read from %s.%s, want people.%s", i, columns[i].Table, columns[i].TableColumn, want)
+		}
+	}
+
+	// The table has changed since the query was parsed.
+	if table.source([]Column{{Name: "id"}}) != nil {
+		t.Error("result with fewer columns than selected is editable")
+	}
+
+	table.items = []string{"missing"}
+	if table.source([]Column{{Name: "missing"}}) != nil {
+		t.Error("result with an unknown column is editable")
+	}
+}
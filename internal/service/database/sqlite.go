@@ -0,0 +1,294 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/davesavic/lazydb/internal/service/config"
+	_ "modernc.org/sqlite"
+)
+
+var _ DatabaseIntegration = (*SQLite)(nil)
+
+// SQLiteDriverName is the connection type the SQLite driver is registered under.
+const SQLiteDriverName = "sqlite"
+
+// uriPathEscaper escapes the characters that end the path of a file URI, and
+// the escape character itself.
+var uriPathEscaper = strings.NewReplacer("%", "%25", "?", "%3F", "#", "%23")
+
+func init() {
+	Register(SQLiteDriverName, func() DatabaseIntegration { return NewSQLite() })
+}
+
+// SQLite is a DatabaseIntegration for local SQLite database files.
+type SQLite struct {
+	sqlConn
+}
+
+// Name implements DatabaseIntegration.
+func (s *SQLite) Name() string {
+	return SQLiteDriverName
+}
+
+func NewSQLite() *SQLite {
+	return &SQLite{}
+}
+
+func (s *SQLite) Connect(connCfg config.ConnectionConfig) error {
+	// SQLite silently creates missing files, which hides typos in the path.
+	_, err := os.Stat(connCfg.Path)
+	if err != nil {
+		return fmt.Errorf("could not open database file: %w", err)
+	}
+
+	// The path is given as a URI, so that it cannot be mistaken for the
+	// parameters that follow it.
+	dsn := "file:" + uriPathEscaper.Replace(connCfg.Path)
+	if connCfg.ReadOnly {
+		dsn += "?_pragma=query_only(1)"
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("could not connect to database: %w", err)
+	}
+
+	// Attached databases, pragmas and temporary tables belong to the
+	// connection they are made on.
+	s.pin(db)
+
+	err = s.db.PingContext(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not ping database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) GetTables() ([]string, error) {
+	rows, err := s.queryContext(context.Background(), "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return nil, fmt.Errorf("could not get tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		err = rows.Scan(&table)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan table: %w", err)
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+func (s *SQLite) Run(ctx context.Context, query string, args ...any) (*QueryResult, error) {
+	if !s.Dialect().returnsRows(query) {
+		return s.exec(ctx, s.Dialect(), query, args...)
+	}
+
+	table := lookupSQLTable(ctx, s.Dialect(), query, s.tableColumns)
+
+	rows, err := s.queryContext(ctx, query, args...)
+	if err != nil {
+		return nil, newQueryError(query, err)
+	}
+
+	result, err := newSQLQueryResult(query, rows, sqliteValue)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Source = table.source(result.Columns)
+
+	return result, nil
+}
+
+// ExecTx implements DatabaseIntegration.
+func (s *SQLite) ExecTx(ctx context.Context, statements []Statement) error {
+	return s.execTx(ctx, statements)
+}
+
+// Begin implements DatabaseIntegration.
+func (s *SQLite) Begin(context.Context) error {
+	return s.begin()
+}
+
+// Commit implements DatabaseIntegration.
+func (s *SQLite) Commit(context.Context) error {
+	return s.commit()
+}
+
+// Rollback implements DatabaseIntegration.
+func (s *SQLite) Rollback(context.Context) error {
+	return s.rollback()
+}
+
+// EstimateRows implements DatabaseIntegration. SQLite's query plans hold no
+// row estimates, and counting the rows would scan every table the query
+// reads.
+func (s *SQLite) EstimateRows(context.Context, string) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// Catalog implements DatabaseIntegration. The schemas are the main database
+// and the attached ones, and the columns are only listed for the main one.
+func (s *SQLite) Catalog(ctx context.Context) (*Catalog, error) {
+	return loadCatalog(ctx, catalogQueries{
+		schemas: "SELECT name FROM pragma_database_list ORDER BY seq",
+		columns: `
+			SELECT 'main', m.name, c.name FROM sqlite_master m JOIN pragma_table_info(m.name) c
+			WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite_%'
+			ORDER BY m.name, c.cid`,
+		functions: "SELECT DISTINCT name FROM pragma_function_list WHERE name GLOB '[a-z_]*' ORDER BY name",
+	}, s.scanRows)
+}
+
+// Objects implements DatabaseIntegration. The schemas are the main database
+// and the attached ones. Foreign keys have no names, so they are listed by
+// their columns and the table they refer to.
+func (s *SQLite) Objects(ctx context.Context, path []Object) ([]Object, error) {
+	switch len(path) {
+	case 0:
+		return loadObjects(ctx, s.scanRows, nil,
+			"SELECT 'schema', name, COALESCE(file, '') FROM pragma_database_list ORDER BY seq")
+
+	case 1:
+		return loadObjects(ctx, s.scanRows, nil, `
+			SELECT type, name, '' FROM `+s.Dialect().QuoteIdentifier(path[0].Name)+`.sqlite_master
+			WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
+			ORDER BY name`)
+
+	case 2:
+		return loadObjects(ctx, s.scanRows, []any{path[1].Name, path[0].Name}, `
+			SELECT 'column', name, type || CASE WHEN "notnull" THEN ' not null' ELSE '' END
+			FROM pragma_table_info(?1, ?2)
+			ORDER BY cid`, `
+			SELECT 'index', name, CASE WHEN origin = 'pk' THEN 'primary' WHEN "unique" THEN 'unique' ELSE '' END
+			FROM pragma_index_list(?1, ?2)
+			ORDER BY name`, `
+			SELECT 'constraint', group_concat("from", ', ') || ' → ' || "table", 'foreign key'
+			FROM pragma_foreign_key_list(?1, ?2)
+			GROUP BY id
+			ORDER BY id`)
+	}
+
+	return nil, nil
+}
+
+// Structure implements DatabaseIntegration. An empty schema means the main
+// database. SQLite only keeps the statement that created a table, so its
+// check constraints are read from it.
+func (s *SQLite) Structure(ctx context.Context, schema, table string) (*TableStructure, error) {
+	if schema == "" {
+		schema = "main"
+	}
+	master := s.Dialect().QuoteIdentifier(schema) + ".sqlite_master"
+
+	var statement sql.NullString
+	err := s.queryRow(ctx, "SELECT sql FROM "+master+" WHERE type IN ('table', 'view') AND name = ?", table).Scan(&statement)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("could not find table %q", table)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find table: %w", err)
+	}
+
+	// Generated columns are hidden from table_info, but not table_xinfo.
+	structure, err := loadStructure(ctx, structureQueries{
+		columns: `
+			SELECT name, type, NOT "notnull",
+				CASE WHEN hidden IN (2, 3) THEN 'generated' ELSE COALESCE(dflt_value, '') END, ''
+			FROM pragma_table_xinfo(?1, ?2)
+			WHERE hidden <> 1
+			ORDER BY cid`,
+		primaryKey: "SELECT name FROM pragma_table_info(?1, ?2) WHERE pk > 0 ORDER BY pk",
+		foreignKeys: `
+			SELECT id, '', "from", '', "table", COALESCE("to", ''), on_update, on_delete
+			FROM pragma_foreign_key_list(?1, ?2)
+			ORDER BY id, seq`,
+		indexes: `
+			SELECT i.name, i."unique", i.origin = 'pk',
+				COALESCE(m.sql, '(' || (SELECT group_concat(name, ', ') FROM pragma_index_info(i.name, ?2)) || ')', '')
+			FROM pragma_index_list(?1, ?2) i
+				LEFT JOIN ` + master + ` m ON m.type = 'index' AND m.name = i.name
+			ORDER BY i.name`,
+		triggers: `
+			SELECT name, sql FROM ` + master + `
+			WHERE type = 'trigger' AND tbl_name = ?1
+			ORDER BY name`,
+	}, []any{table, schema}, s.scanRows)
+	if err != nil {
+		return nil, err
+	}
+
+	structure.Checks = s.Dialect().checks(statement.String)
+
+	return structure, nil
+}
+
+// Dialect implements DatabaseIntegration.
+func (s *SQLite) Dialect() Dialect {
+	return DialectSQLite
+}
+
+// tableColumns implements the lookup of lookupSQLTable. An empty schema
+// means the main database.
+func (s *SQLite) tableColumns(ctx context.Context, schema, table string) ([]TableColumn, error) {
+	if schema == "" {
+		schema = "main"
+	}
+
+	// An INTEGER PRIMARY KEY is an alias for the rowid, which is assigned
+	// automatically. Generated columns are hidden from table_info.
+	rows, err := s.queryContext(ctx, `
+		SELECT name, type, NOT "notnull", pk > 0,
+			CASE WHEN pk = 1 AND upper(type) = 'INTEGER' AND (SELECT count(*) FROM pragma_table_info(?1, ?2) WHERE pk > 0) = 1
+				THEN 'rowid' ELSE COALESCE(dflt_value, '') END
+		FROM pragma_table_info(?1, ?2)`, table, schema)
+	if err != nil {
+		return nil, fmt.Errorf("could not get table columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []TableColumn
+	for rows.Next() {
+		var col TableColumn
+		err = rows.Scan(&col.Name, &col.TypeName, &col.Nullable, &col.PrimaryKey, &col.Default)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan table column: %w", err)
+		}
+
+		columns = append(columns, col)
+	}
+
+	return columns, rows.Err()
+}
+
+func (s *SQLite) Close() error {
+	if s.db == nil {
+		return nil
+	}
+
+	if s.tx != nil {
+		_ = s.tx.Rollback()
+	}
+
+	return s.db.Close()
+}
+
+// sqliteValue converts a value scanned from the SQLite driver. The driver
+// already returns typed values, with blobs as raw bytes, so they are kept as
+// they are and only formatted for display.
+func sqliteValue(_ string, value any) any {
+	return value
+}
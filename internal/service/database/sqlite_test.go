@@ -0,0 +1,266 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davesavic/lazydb/internal/service/config"
+)
+
+// newTestSQLite connects to a new database file holding a people table.
+func newTestSQLite(t *testing.T) *SQLite {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	err := os.WriteFile(path, nil, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := NewSQLite()
+	err = db.Connect(config.ConnectionConfig{Type: SQLiteDriverName, Path: path})
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	mustRun(t, db, "CREATE TABLE people (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+	mustRun(t, db, "INSERT INTO people (name) VALUES ('Ada'), ('Grace'), ('Linus')")
+
+	return db
+}
+
+// mustRun runs query and fetches all of its rows.
+func mustRun(t *testing.T, db DatabaseIntegration, query string, args ...any) *QueryResult {
+	t.Helper()
+
+	result, err := db.Run(context.Background(), query, args...)
+	if err != nil {
+		t.Fatalf("Run(%q): %v", query, err)
+	}
+
+	for result.HasMore {
+		err = result.Fetch(2)
+		if err != nil {
+			t.Fatalf("Fetch(%q): %v", query, err)
+		}
+	}
+
+	return result
+}
+
+func countPeople(t *testing.T, db DatabaseIntegration) int64 {
+	t.Helper()
+
+	result := mustRun(t, db, "SELECT count(*) FROM people")
+
+	return result.Rows[0][0].(int64)
+}
+
+func TestSQLiteRunFetch(t *testing.T) {
+	db := newTestSQLite(t)
+
+	result := mustRun(t, db, "SELECT id, name FROM people WHERE id > ? ORDER BY id", 1)
+	if len(result.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(result.Rows))
+	}
+	if got := result.Rows[0][1]; got != "Grace" {
+		t.Errorf("first name = %v, want Grace", got)
+	}
+
+	if result.Source == nil || result.Source.Table != "people" {
+		t.Fatalf("Source = %+v, want the people table", result.Source)
+	}
+
+	// The id column holds another column's values, so rows cannot be found
+	// by it.
+	result = mustRun(t, db, "SELECT 4 - id AS id, name FROM people")
+	if result.Source != nil {
+		t.Errorf("result with an aliased key is editable")
+	}
+
+	result = mustRun(t, db, "UPDATE people SET name = upper(name)")
+	if result.Command != "UPDATE" || result.RowsAffected != 3 {
+		t.Errorf("got %s of %d rows, want UPDATE of 3", result.Command, result.RowsAffected)
+	}
+}
+
+func TestSQLiteFetchBatches(t *testing.T) {
+	db := newTestSQLite(t)
+
+	result, err := db.Run(context.Background(), "SELECT name FROM people ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = result.Fetch(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 2 || !result.HasMore {
+		t.Fatalf("got %d rows, more %v, want 2 rows and more", len(result.Rows), result.HasMore)
+	}
+
+	err = result.Fetch(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 3 || result.HasMore {
+		t.Fatalf("got %d rows, more %v, want 3 rows and no more", len(result.Rows), result.HasMore)
+	}
+}
+
+func TestSQLiteEstimateRows(t *testing.T) {
+	db := newTestSQLite(t)
+
+	// Estimates never count the rows.
+	_, err := db.EstimateRows(context.Background(), "SELECT * FROM people")
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("EstimateRows error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestSQLiteSessionState(t *testing.T) {
+	db := newTestSQLite(t)
+
+	// Temporary tables only exist on the connection that made them.
+	mustRun(t, db, "CREATE TEMP TABLE scratch (n INTEGER)")
+	mustRun(t, db, "INSERT INTO scratch VALUES (1)")
+
+	result := mustRun(t, db, "SELECT n FROM scratch")
+	if len(result.Rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(result.Rows))
+	}
+}
+
+func TestSQLiteTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLite(t)
+
+	err := db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, db, "INSERT INTO people (name) VALUES ('Barbara')")
+
+	// Editable results look their table up inside the transaction.
+	result := mustRun(t, db, "SELECT id, name FROM people")
+	if len(result.Rows) != 4 || result.Source == nil {
+		t.Fatalf("got %d rows, source %v, want 4 editable rows", len(result.Rows), result.Source)
+	}
+
+	err = db.Rollback(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := countPeople(t, db); got != 3 {
+		t.Fatalf("after rollback got %d people, want 3", got)
+	}
+
+	err = db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, db, "INSERT INTO people (name) VALUES ('Barbara')")
+	err = db.Commit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := countPeople(t, db); got != 4 {
+		t.Fatalf("after commit got %d people, want 4", got)
+	}
+}
+
+func TestSQLiteExecTx(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLite(t)
+
+	result := mustRun(t, db, "SELECT id, name FROM people ORDER BY id")
+	source := result.Source
+	if source == nil {
+		t.Fatal("result is not editable")
+	}
+
+	d := db.Dialect()
+	err := db.ExecTx(ctx, []Statement{
+		d.Update(source, []Assignment{{Column: "name", Value: "Ada Lovelace"}}, []any{int64(1)}),
+		d.Delete(source, []any{int64(2)}),
+		d.Insert(source, []Assignment{{Column: "name", Value: "Margaret"}}),
+	})
+	if err != nil {
+		t.Fatalf("ExecTx: %v", err)
+	}
+
+	result = mustRun(t, db, "SELECT name FROM people ORDER BY id")
+	want := []string{"Ada Lovelace", "Linus", "Margaret"}
+	if len(result.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(result.Rows), len(want))
+	}
+	for i, name := range want {
+		if got := result.Rows[i][0]; got != name {
+			t.Errorf("row %d = %v, want %s", i, got, name)
+		}
+	}
+
+	// A failing statement rolls back the ones before it.
+	err = db.ExecTx(ctx, []Statement{
+		d.Delete(source, []any{int64(1)}),
+		d.Insert(source, []Assignment{{Column: "name", Value: nil}}),
+	})
+	if err == nil {
+		t.Fatal("ExecTx succeeded, want a NOT NULL violation")
+	}
+	if got := countPeople(t, db); got != 3 {
+		t.Errorf("after failed ExecTx got %d people, want 3", got)
+	}
+
+	// Inside an open transaction only the failing changes are rolled back.
+	err = db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, db, "DELETE FROM people WHERE name = 'Linus'")
+	err = db.ExecTx(ctx, []Statement{d.Insert(source, []Assignment{{Column: "name", Value: nil}})})
+	if err == nil {
+		t.Fatal("ExecTx succeeded, want a NOT NULL violation")
+	}
+	err = db.Commit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := countPeople(t, db); got != 2 {
+		t.Errorf("after commit got %d people, want 2", got)
+	}
+}
+
+func TestSQLiteReadOnlyPath(t *testing.T) {
+	// Characters that URIs give a meaning to are part of the path.
+	path := filepath.Join(t.TempDir(), "what?#100%.db")
+	err := os.WriteFile(path, nil, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := NewSQLite()
+	err = db.Connect(config.ConnectionConfig{Type: SQLiteDriverName, Path: path, ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Run(context.Background(), "CREATE TABLE people (id INTEGER PRIMARY KEY)")
+	if err == nil {
+		t.Error("read-only connection created a table")
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want only the database file", len(entries))
+	}
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// TableStructure describes how a table or view is defined.
+type TableStructure struct {
+	Columns []StructureColumn
+	// PrimaryKey holds the columns of the primary key, in order, or nothing
+	// if there is none.
+	PrimaryKey  []string
+	ForeignKeys []ForeignKey
+	Indexes     []Index
+	Checks      []Check
+	Triggers    []Trigger
+}
+
+// StructureColumn is a column of a TableStructure.
+type StructureColumn struct {
+	Name     string
+	Type     string
+	Nullable bool
+	// Default is the expression of the column's default value, or how it is
+	// generated, e.g. "auto_increment". It is empty if there is none.
+	Default string
+	Comment string
+}
+
+// ForeignKey is a foreign key of a TableStructure. Its name is empty in
+// databases that do not name foreign keys.
+type ForeignKey struct {
+	Name    string
+	Columns []string
+	// RefSchema, RefTable and RefColumns are the table and columns the key
+	// refers to. RefColumns is empty if the key refers to the primary key
+	// without naming its columns.
+	RefSchema  string
+	RefTable   string
+	RefColumns []string
+	// OnUpdate and OnDelete are the referential actions, such as "CASCADE".
+	OnUpdate string
+	OnDelete string
+}
+
+// Index is an index of a TableStructure.
+type Index struct {
+	Name    string
+	Unique  bool
+	Primary bool
+	// Definition is the statement that creates the index, or the columns it
+	// is on if the database has no such statement for it.
+	Definition string
+}
+
+// Check is a check constraint of a TableStructure.
+type Check struct {
+	Name       string
+	Expression string
+}
+
+// Trigger is a trigger of a TableStructure.
+type Trigger struct {
+	Name       string
+	Definition string
+}
+
+// structureQueries are the queries that describe a table for a
+// TableStructure. Each one is run with the same arguments, and is skipped if
+// it is empty.
+type structureQueries struct {
+	// columns returns the name, type, nullability, default and comment of
+	// each column.
+	columns string
+	// primaryKey returns the columns of the primary key, in order.
+	primaryKey string
+	// foreignKeys returns a row for each column of each foreign key, ordered
+	// by key: an identifier of the key, its name, the column, the schema,
+	// table and column it refers to, and the update and delete actions.
+	foreignKeys string
+	// indexes returns the name, uniqueness, whether it is the primary key and
+	// the definition of each index.
+	indexes string
+	// checks returns the name and expression of each check constraint.
+	checks string
+	// triggers returns the name and definition of each trigger.
+	triggers string
+}
+
+// loadStructure describes a table with queries.
+func loadStructure(ctx context.Context, queries structureQueries, args []any, scanRows scanRowsFunc) (*TableStructure, error) {
+	var structure TableStructure
+
+	err := scanStructure(ctx, queries.columns, args, scanRows, func(scan func(...any) error) error {
+		var col StructureColumn
+		if err := scan(&col.Name, &col.Type, &col.Nullable, &col.Default, &col.Comment); err != nil {
+			return err
+		}
+
+		structure.Columns = append(structure.Columns, col)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get columns: %w", err)
+	}
+
+	err = scanStructure(ctx, queries.primaryKey, args, scanRows, func(scan func(...any) error) error {
+		var column string
+		if err := scan(&column); err != nil {
+			return err
+		}
+
+		structure.PrimaryKey = append(structure.PrimaryKey, column)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get primary key: %w", err)
+	}
+
+	var lastKey string
+	err = scanStructure(ctx, queries.foreignKeys, args, scanRows, func(scan func(...any) error) error {
+		var key, column, refColumn string
+		var fk ForeignKey
+		if err := scan(&key, &fk.Name, &column, &fk.RefSchema, &fk.RefTable, &refColumn, &fk.OnUpdate, &fk.OnDelete); err != nil {
+			return err
+		}
+
+		if len(structure.ForeignKeys) == 0 || key != lastKey {
+			structure.ForeignKeys = append(structure.ForeignKeys, fk)
+			lastKey = key
+		}
+
+		last := &structure.ForeignKeys[len(structure.ForeignKeys)-1]
+		last.Columns = append(last.Columns, column)
+		if refColumn != "" {
+			last.RefColumns = append(last.RefColumns, refColumn)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get foreign keys: %w", err)
+	}
+
+	err = scanStructure(ctx, queries.indexes, args, scanRows, func(scan func(...any) error) error {
+		var index Index
+		if err := scan(&index.Name, &index.Unique, &index.Primary, &index.Definition); err != nil {
+			return err
+		}
+
+		structure.Indexes = append(structure.Indexes, index)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get indexes: %w", err)
+	}
+
+	err = scanStructure(ctx, queries.checks, args, scanRows, func(scan func(...any) error) error {
+		var check Check
+		if err := scan(&check.Name, &check.Expression); err != nil {
+			return err
+		}
+
+		structure.Checks = append(structure.Checks, check)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get check constraints: %w", err)
+	}
+
+	err = scanStructure(ctx, queries.triggers, args, scanRows, func(scan func(...any) error) error {
+		var trigger Trigger
+		if err := scan(&trigger.Name, &trigger.Definition); err != nil {
+			return err
+		}
+
+		structure.Triggers = append(structure.Triggers, trigger)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get triggers: %w", err)
+	}
+
+	return &structure, nil
+}
+
+// scanStructure runs query with scanRows, unless it is empty.
+func scanStructure(ctx context.Context, query string, args []any, scanRows scanRowsFunc, fn func(scan func(...any) error) error) error {
+	if query == "" {
+		return nil
+	}
+
+	return scanRows(ctx, query, args, fn)
+}
+
+// checks finds the check constraints in a CREATE TABLE statement, for
+// databases that only keep the statement.
+func (d Dialect) checks(statement string) []Check {
+	tokens := d.lex(statement)
+
+	var checks []Check
+	for i, t := range tokens {
+		if !t.is("CHECK") || i+1 >= len(tokens) || tokens[i+1].text != "(" {
+			continue
+		}
+
+		open := tokens[i+1]
+		end := len(statement)
+		for _, c := range tokens[i+2:] {
+			if c.text == ")" && c.depth == open.depth {
+				end = c.pos + 1
+				break
+			}
+		}
+
+		var check Check
+		if i >= 2 && tokens[i-2].is("CONSTRAINT") {
+			check.Name = unquoteIdentifier(tokens[i-1].text)
+		}
+		check.Expression = "CHECK " + statement[open.pos:end]
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
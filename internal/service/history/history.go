@@ -0,0 +1,115 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPath is the file the history is kept in, next to
+	// connections.toml.
+	DefaultPath = "history.jsonl"
+	// MaxEntries is the number of most recent entries Entries returns.
+	MaxEntries = 1000
+)
+
+// Entry is a query that was run, as it is recorded in the history.
+type Entry struct {
+	Connection string        `json:"connection"`
+	Query      string        `json:"query"`
+	Time       time.Time     `json:"time"`
+	Duration   time.Duration `json:"duration"`
+	// Rows is the number of rows the query returned in its first batch, or
+	// the number of rows it changed if it returns none. More is set if the
+	// query had rows left to fetch.
+	Rows int64 `json:"rows"`
+	More bool  `json:"more,omitempty"`
+	// Error is the error the query failed with, or empty if it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// Store keeps the history in a file with an entry per line, so that
+// recording a query only appends to it.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewStore(path string) *Store {
+	return &Store{
+		path: path,
+	}
+}
+
+// Add appends an entry to the history. It is safe to call from the commands
+// that run queries.
+func (s *Store) Add(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not encode history entry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("could not open history: %w", err)
+	}
+
+	_, err = f.Write(append(line, '\n'))
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("could not write history: %w", err)
+	}
+
+	return f.Close()
+}
+
+// Entries returns the most recent entries of the history, newest first.
+// Lines that cannot be read, such as one cut short by a crash, are skipped.
+func (s *Store) Entries() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			slog.Debug("history.Entries", "error", err)
+			continue
+		}
+
+		entries = append(entries, entry)
+		if len(entries) > 2*MaxEntries {
+			entries = slices.Delete(entries, 0, len(entries)-MaxEntries)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read history: %w", err)
+	}
+
+	entries = entries[max(len(entries)-MaxEntries, 0):]
+	slices.Reverse(entries)
+
+	return entries, nil
+}
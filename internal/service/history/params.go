@@ -0,0 +1,124 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const (
+	// DefaultParamsPath is the file the values given for query parameters
+	// are kept in, next to the history.
+	DefaultParamsPath = "params.json"
+	// MaxParamScripts is the number of scripts whose parameter values are
+	// kept. The values given longest ago are dropped first.
+	MaxParamScripts = 200
+)
+
+// ParamValue is the value given for a query parameter, as it was typed, and
+// the type it was given as.
+type ParamValue struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// paramScript is the values given for the parameters of a script.
+type paramScript struct {
+	Script string                `json:"script"`
+	Values map[string]ParamValue `json:"values"`
+}
+
+// ParamStore keeps the values last given for the parameters of each script,
+// so they can be offered again. The file is rewritten whenever values are
+// given, with the most recent script last.
+type ParamStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewParamStore(path string) *ParamStore {
+	return &ParamStore{
+		path: path,
+	}
+}
+
+// Get returns the values last given for the parameters of script, by name.
+func (s *ParamStore) Get(script string) (map[string]ParamValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scripts, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(scripts, func(p paramScript) bool { return p.Script == script })
+	if i < 0 {
+		return nil, nil
+	}
+
+	return scripts[i].Values, nil
+}
+
+// Set records the values given for the parameters of script.
+func (s *ParamStore) Set(script string, values map[string]ParamValue) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scripts, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	scripts = slices.DeleteFunc(scripts, func(p paramScript) bool { return p.Script == script })
+	scripts = append(scripts, paramScript{Script: script, Values: values})
+	scripts = scripts[max(len(scripts)-MaxParamScripts, 0):]
+
+	data, err := json.Marshal(scripts)
+	if err != nil {
+		return fmt.Errorf("could not encode parameter values: %w", err)
+	}
+
+	// The values are written to a temporary file first, so that a crash
+	// cannot leave the file cut short.
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not save parameter values: %w", err)
+	}
+
+	_, err = tmp.Write(data)
+	err = errors.Join(err, tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("could not save parameter values: %w", err)
+	}
+
+	return nil
+}
+
+// load reads the scripts from the file. A missing file holds none.
+func (s *ParamStore) load() ([]paramScript, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read parameter values: %w", err)
+	}
+
+	var scripts []paramScript
+	err = json.Unmarshal(data, &scripts)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode parameter values: %w", err)
+	}
+
+	return scripts, nil
+}
@@ -0,0 +1,47 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestParamStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultParamsPath)
+	store := NewParamStore(path)
+
+	values, err := store.Get("SELECT :id")
+	if err != nil || values != nil {
+		t.Fatalf("Get before Set = %v, %v, want no values", values, err)
+	}
+
+	err = store.Set("SELECT :id", map[string]ParamValue{"id": {Type: "number", Text: "42"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The values outlive the store that was given them.
+	values, err = NewParamStore(path).Get("SELECT :id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := values["id"]; got.Type != "number" || got.Text != "42" {
+		t.Errorf("id = %+v, want the number 42", got)
+	}
+
+	for i := range MaxParamScripts {
+		err = store.Set(fmt.Sprintf("SELECT :id + %d", i), map[string]ParamValue{"id": {Type: "text"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	values, err = store.Get("SELECT :id")
+	if err != nil || values != nil {
+		t.Errorf("oldest script = %v, %v, want it dropped", values, err)
+	}
+	values, err = store.Get("SELECT :id + 0")
+	if err != nil || values == nil {
+		t.Errorf("oldest kept script = %v, %v, want its values", values, err)
+	}
+}
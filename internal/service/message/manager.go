@@ -2,6 +2,7 @@ package message
 
 import (
 	"log/slog"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/davesavic/lazydb/internal/service/database"
@@ -46,6 +47,11 @@ type ScreenName string
 const (
 	ScreenNameMain          ScreenName = "main"
 	ScreenNameNewConnection ScreenName = "newConnection"
+	ScreenNameConfirm       ScreenName = "confirm"
+	ScreenNameHistory       ScreenName = "history"
+	ScreenNameSnippets      ScreenName = "snippets"
+	ScreenNameParams        ScreenName = "params"
+	ScreenNameStructure     ScreenName = "structure"
 )
 
 type ChangeScreenMsg struct {
@@ -78,6 +84,10 @@ type NewAddConnectionMsg struct {
 	Database string
 	User     string
 	Password string
+	Path     string
+	ReadOnly bool
+	Label    string
+	Color    string
 }
 
 func (m *Manager) NewAddConnectionCmd(msg NewAddConnectionMsg) tea.Cmd {
@@ -89,6 +99,9 @@ func (m *Manager) NewAddConnectionCmd(msg NewAddConnectionMsg) tea.Cmd {
 
 type LoadConnectionMsg struct {
 	Name string
+	// Confirmed is set once the user has agreed to leave a session with an
+	// open transaction.
+	Confirmed bool
 }
 
 func (m *Manager) NewLoadConnectionCmd(msg LoadConnectionMsg) tea.Cmd {
@@ -98,37 +111,414 @@ func (m *Manager) NewLoadConnectionCmd(msg LoadConnectionMsg) tea.Cmd {
 	}
 }
 
-type NewConnectionLoadedMsg struct{}
+type NewConnectionLoadedMsg struct {
+	Session string
+}
+
+func (m *Manager) NewNewConnectionLoadedCmd(session string) tea.Cmd {
+	slog.Debug("NewNewConnectionLoadedCmd", "session", session)
+	return func() tea.Msg {
+		return NewConnectionLoadedMsg{
+			Session: session,
+		}
+	}
+}
+
+// LoadCatalogMsg loads the names a session's queries can refer to, for
+// completion.
+type LoadCatalogMsg struct {
+	Session string
+}
 
-func (m *Manager) NewNewConnectionLoadedCmd() tea.Cmd {
-	slog.Debug("NewNewConnectionLoadedCmd")
+func (m *Manager) NewLoadCatalogCmd(session string) tea.Cmd {
+	slog.Debug("NewLoadCatalogCmd", "session", session)
 	return func() tea.Msg {
-		return NewConnectionLoadedMsg{}
+		return LoadCatalogMsg{
+			Session: session,
+		}
+	}
+}
+
+// CatalogLoadedMsg carries a session's catalog, or the error that kept it
+// from loading.
+type CatalogLoadedMsg struct {
+	Session string
+	Catalog *database.Catalog
+	Err     error
+}
+
+// LoadObjectsMsg loads the objects inside the object at Path of a session's
+// database, for the schema browser.
+type LoadObjectsMsg struct {
+	Session string
+	Path    []database.Object
+}
+
+func (m *Manager) NewLoadObjectsCmd(session string, path []database.Object) tea.Cmd {
+	slog.Debug("NewLoadObjectsCmd", "session", session, "path", path)
+	return func() tea.Msg {
+		return LoadObjectsMsg{
+			Session: session,
+			Path:    path,
+		}
+	}
+}
+
+// ObjectsLoadedMsg carries the objects inside the object at Path, or the
+// error that kept them from loading.
+type ObjectsLoadedMsg struct {
+	Session string
+	Path    []database.Object
+	Objects []database.Object
+	Err     error
+}
+
+// LoadStructureMsg loads the structure of a table of a session's database.
+// An empty Schema means the default one.
+type LoadStructureMsg struct {
+	Session string
+	Schema  string
+	Table   string
+}
+
+func (m *Manager) NewLoadStructureCmd(session, schema, table string) tea.Cmd {
+	slog.Debug("NewLoadStructureCmd", "session", session, "schema", schema, "table", table)
+	return func() tea.Msg {
+		return LoadStructureMsg{
+			Session: session,
+			Schema:  schema,
+			Table:   table,
+		}
+	}
+}
+
+// StructureLoadedMsg carries the structure of a table, or the error that
+// kept it from loading.
+type StructureLoadedMsg struct {
+	Session   string
+	Schema    string
+	Table     string
+	Structure *database.TableStructure
+	Err       error
+}
+
+type CloseConnectionMsg struct {
+	Name string
+	// Confirmed is set once the user has agreed to roll back the session's
+	// open transaction.
+	Confirmed bool
+}
+
+func (m *Manager) NewCloseConnectionCmd(msg CloseConnectionMsg) tea.Cmd {
+	slog.Debug("NewCloseConnectionCmd", "msg", msg)
+	return func() tea.Msg {
+		return msg
 	}
 }
 
+// SessionsChangedMsg is sent whenever a session is opened, closed or switched to.
+type SessionsChangedMsg struct {
+	Active   string
+	Sessions []string
+}
+
+func (m *Manager) NewSessionsChangedCmd(active string, sessions []string) tea.Cmd {
+	slog.Debug("NewSessionsChangedCmd", "active", active, "sessions", sessions)
+	return func() tea.Msg {
+		return SessionsChangedMsg{
+			Active:   active,
+			Sessions: sessions,
+		}
+	}
+}
+
+// NextSessionMsg switches to the session after the active one.
+type NextSessionMsg struct{}
+
+// QuitMsg closes every session and quits.
+type QuitMsg struct{}
+
+// ConfirmMsg asks the user to confirm an action before Msg is sent.
+type ConfirmMsg struct {
+	Prompt string
+	Msg    tea.Msg
+}
+
+func (m *Manager) NewConfirmCmd(prompt string, msg tea.Msg) tea.Cmd {
+	slog.Debug("NewConfirmCmd", "prompt", prompt, "msg", msg)
+	return func() tea.Msg {
+		return ConfirmMsg{
+			Prompt: prompt,
+			Msg:    msg,
+		}
+	}
+}
+
+// TransactionChangedMsg is sent whenever a session's transaction state
+// changes.
+type TransactionChangedMsg struct {
+	Session string
+	// Manual reports whether statements run in explicit transactions.
+	Manual bool
+	// Open reports whether a transaction is open, and Statements how many
+	// statements have run in it.
+	Open       bool
+	Statements int
+}
+
+func (m *Manager) NewTransactionChangedCmd(session string, manual, open bool, statements int) tea.Cmd {
+	slog.Debug("NewTransactionChangedCmd", "session", session, "manual", manual, "open", open, "statements", statements)
+	return func() tea.Msg {
+		return TransactionChangedMsg{
+			Session:    session,
+			Manual:     manual,
+			Open:       open,
+			Statements: statements,
+		}
+	}
+}
+
+// ResultTarget says which results tab shows a query's results.
+type ResultTarget int
+
+const (
+	// ResultsReplace replaces the results of the previous run.
+	ResultsReplace ResultTarget = iota
+	// ResultsAppend adds a tab for the next statement of a script.
+	ResultsAppend
+	// ResultsRefresh shows the results in the tab that showed them before.
+	ResultsRefresh
+)
+
+// ExecuteQueryMsg runs a query in the named session, or the active session
+// if Session is empty, with Args bound to its placeholders. Remaining holds
+// the statements of a script that run after it, one at a time, until one of
+// them fails.
 type ExecuteQueryMsg struct {
-	Query string
+	Session   string
+	Query     string
+	Args      []any
+	Remaining []database.Statement
+	Target    ResultTarget
+	// Confirmed is set once the user has agreed to run a query that may
+	// destroy data.
+	Confirmed bool
+}
+
+func (m *Manager) NewExecuteQueryCmd(query string, args ...any) tea.Cmd {
+	slog.Debug("NewExecuteQueryCmd", "query", query, "args", len(args))
+	return func() tea.Msg {
+		return ExecuteQueryMsg{
+			Query: query,
+			Args:  args,
+		}
+	}
+}
+
+// NewExecuteScriptCmd runs statements one after the other in the active
+// session, showing the results of each in its own tab.
+func (m *Manager) NewExecuteScriptCmd(statements []database.Statement) tea.Cmd {
+	slog.Debug("NewExecuteScriptCmd", "statements", len(statements))
+	if len(statements) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		return ExecuteQueryMsg{
+			Query:     statements[0].SQL,
+			Args:      statements[0].Args,
+			Remaining: statements[1:],
+		}
+	}
 }
 
-func (m *Manager) NewExecuteQueryCmd(query string) tea.Cmd {
-	slog.Debug("NewExecuteQueryCmd", "query", query)
+// PromptParamsMsg asks for the values of the parameters of statements, which
+// are then run as a script with the values bound as arguments.
+type PromptParamsMsg struct {
+	Statements []database.Statement
+}
+
+func (m *Manager) NewPromptParamsCmd(statements []database.Statement) tea.Cmd {
+	slog.Debug("NewPromptParamsCmd", "statements", len(statements))
+	return func() tea.Msg {
+		return PromptParamsMsg{
+			Statements: statements,
+		}
+	}
+}
+
+// NewRefreshQueryCmd runs a query again in the named session to refresh the
+// results it showed.
+func (m *Manager) NewRefreshQueryCmd(session string, query string, args []any) tea.Cmd {
+	slog.Debug("NewRefreshQueryCmd", "session", session, "query", query, "args", len(args))
 	return func() tea.Msg {
 		return ExecuteQueryMsg{
+			Session: session,
+			Query:   query,
+			Args:    args,
+			Target:  ResultsRefresh,
+		}
+	}
+}
+
+// ShowHistoryMsg opens the history of queries that were run.
+type ShowHistoryMsg struct{}
+
+func (m *Manager) NewShowHistoryCmd() tea.Cmd {
+	slog.Debug("NewShowHistoryCmd")
+	return func() tea.Msg {
+		return ShowHistoryMsg{}
+	}
+}
+
+// ShowSnippetsMsg opens the snippets that can be run on the active session.
+type ShowSnippetsMsg struct{}
+
+func (m *Manager) NewShowSnippetsCmd() tea.Cmd {
+	slog.Debug("NewShowSnippetsCmd")
+	return func() tea.Msg {
+		return ShowSnippetsMsg{}
+	}
+}
+
+// LoadQueryMsg replaces the query in the active session's editor, and runs
+// it if Run is set.
+type LoadQueryMsg struct {
+	Query string
+	Run   bool
+}
+
+func (m *Manager) NewLoadQueryCmd(query string, run bool) tea.Cmd {
+	slog.Debug("NewLoadQueryCmd", "query", query, "run", run)
+	return func() tea.Msg {
+		return LoadQueryMsg{
 			Query: query,
+			Run:   run,
 		}
 	}
 }
 
+// DangerousQueryMsg asks the user to confirm a query that may destroy data.
+// Dangers describes each of its dangerous statements and the rows they are
+// estimated to affect.
+type DangerousQueryMsg struct {
+	Session string
+	Query   ExecuteQueryMsg
+	Dangers []string
+}
+
+type QueryStartedMsg struct {
+	Session   string
+	Query     string
+	Args      []any
+	Target    ResultTarget
+	StartedAt time.Time
+}
+
+func (m *Manager) NewQueryStartedCmd(session string, query string, args []any, target ResultTarget) tea.Cmd {
+	slog.Debug("NewQueryStartedCmd", "session", session, "query", query, "args", len(args), "target", target)
+	startedAt := time.Now()
+	return func() tea.Msg {
+		return QueryStartedMsg{
+			Session:   session,
+			Query:     query,
+			Args:      args,
+			Target:    target,
+			StartedAt: startedAt,
+		}
+	}
+}
+
+// QueryExecutedMsg is sent once a query has run and the first batch of its
+// rows has been fetched. Remaining holds the statements of its script that
+// are still to run.
 type QueryExecutedMsg struct {
-	Result *database.QueryResult
+	Session   string
+	Result    *database.QueryResult
+	Duration  time.Duration
+	Remaining []database.Statement
 }
 
-func (m *Manager) NewQueryExecutedCmd(result *database.QueryResult) tea.Cmd {
-	slog.Debug("NewQueryExecutedCmd", "result", result)
+func (m *Manager) NewQueryExecutedCmd(session string, result *database.QueryResult, duration time.Duration) tea.Cmd {
+	slog.Debug("NewQueryExecutedCmd", "session", session, "result", result, "duration", duration)
 	return func() tea.Msg {
 		return QueryExecutedMsg{
-			Result: result,
+			Session:  session,
+			Result:   result,
+			Duration: duration,
+		}
+	}
+}
+
+// FetchRowsMsg requests the next batch of rows of a session's open result set.
+type FetchRowsMsg struct {
+	Session string
+}
+
+func (m *Manager) NewFetchRowsCmd(session string) tea.Cmd {
+	slog.Debug("NewFetchRowsCmd", "session", session)
+	return func() tea.Msg {
+		return FetchRowsMsg{
+			Session: session,
+		}
+	}
+}
+
+// RowsFetchedMsg is sent once more rows have been added to a result set.
+type RowsFetchedMsg struct {
+	Session string
+	Result  *database.QueryResult
+}
+
+type QueryCancelledMsg struct {
+	Session string
+}
+
+func (m *Manager) NewQueryCancelledCmd(session string) tea.Cmd {
+	slog.Debug("NewQueryCancelledCmd", "session", session)
+	return func() tea.Msg {
+		return QueryCancelledMsg{
+			Session: session,
+		}
+	}
+}
+
+// ApplyChangesMsg executes statements that change rows of a result set in a
+// single transaction.
+type ApplyChangesMsg struct {
+	Session    string
+	Statements []database.Statement
+}
+
+func (m *Manager) NewApplyChangesCmd(session string, statements []database.Statement) tea.Cmd {
+	slog.Debug("NewApplyChangesCmd", "session", session, "statements", len(statements))
+	return func() tea.Msg {
+		return ApplyChangesMsg{
+			Session:    session,
+			Statements: statements,
+		}
+	}
+}
+
+// ChangesAppliedMsg is sent once the statements of an ApplyChangesMsg have
+// been committed.
+type ChangesAppliedMsg struct {
+	Session    string
+	Statements int
+}
+
+// ErrorMsg reports an error to the user. Session is the session the error
+// occurred in, or empty if it is not tied to one.
+type ErrorMsg struct {
+	Session string
+	Err     error
+}
+
+func (m *Manager) NewErrorCmd(err error) tea.Cmd {
+	slog.Debug("NewErrorCmd", "error", err)
+	return func() tea.Msg {
+		return ErrorMsg{
+			Err: err,
 		}
 	}
 }
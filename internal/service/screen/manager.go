@@ -4,8 +4,13 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/davesavic/lazydb/internal/service/message"
 	"github.com/davesavic/lazydb/internal/ui/common"
+	"github.com/davesavic/lazydb/internal/ui/screen/confirm"
 	"github.com/davesavic/lazydb/internal/ui/screen/connection"
+	historyscreen "github.com/davesavic/lazydb/internal/ui/screen/history"
 	mainscreen "github.com/davesavic/lazydb/internal/ui/screen/main"
+	"github.com/davesavic/lazydb/internal/ui/screen/params"
+	"github.com/davesavic/lazydb/internal/ui/screen/snippet"
+	"github.com/davesavic/lazydb/internal/ui/screen/structure"
 )
 
 type ViewScreen interface {
@@ -30,6 +35,11 @@ func NewScreen(props *common.ScreenProps) *Screen {
 
 	screens[message.ScreenNameMain] = mainscreen.NewMain(props)
 	screens[message.ScreenNameNewConnection] = connection.NewNewConnection(props)
+	screens[message.ScreenNameConfirm] = confirm.NewConfirm(props)
+	screens[message.ScreenNameHistory] = historyscreen.NewHistory(props)
+	screens[message.ScreenNameSnippets] = snippet.NewSnippets(props)
+	screens[message.ScreenNameParams] = params.NewParams(props)
+	screens[message.ScreenNameStructure] = structure.NewStructure(props)
 
 	return &Screen{
 		screens: screens,
@@ -51,16 +61,10 @@ func (s *Screen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		s.width = msg.Width
 		s.height = msg.Height
 
-		for i, sc := range s.screens {
-			updatedScreen, cmd := sc.Update(msg)
-			if updatedScreen, ok := updatedScreen.(ViewScreen); ok {
-				s.screens[i] = updatedScreen
-			}
-			cmds = append(cmds, cmd)
-		}
-
 	case message.ChangeScreenMsg:
-		s.previous = s.active
+		if msg.ScreenName != s.active {
+			s.previous = s.active
+		}
 		s.active = msg.ScreenName
 
 		cmds = append(cmds, s.screens[s.active].Init())
@@ -71,6 +75,23 @@ func (s *Screen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Only the active screen handles input. Every other message is seen by
+	// all screens so that background work, such as a running query, still
+	// completes while another screen is shown.
+	if _, ok := msg.(tea.KeyMsg); !ok {
+		for name, sc := range s.screens {
+			if name == s.active {
+				continue
+			}
+
+			updatedScreen, cmd := sc.Update(msg)
+			if updatedScreen, ok := updatedScreen.(ViewScreen); ok {
+				s.screens[name] = updatedScreen
+			}
+			cmds = append(cmds, cmd)
+		}
+	}
+
 	newScreen, cmd := s.screens[s.active].Update(msg)
 	if newScreen, ok := newScreen.(ViewScreen); ok {
 		s.screens[s.active] = newScreen
@@ -0,0 +1,361 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/davesavic/lazydb/internal/service/config"
+	"github.com/davesavic/lazydb/internal/service/database"
+)
+
+// Session is a live connection to a database, opened from a named connection.
+type Session struct {
+	Name     string
+	Config   config.ConnectionConfig
+	Database database.DatabaseIntegration
+
+	// busy is set while a query runs or rows are fetched, as a connection can
+	// only do one thing at a time.
+	busy bool
+	// queryCtx is the context of the latest query, which stays alive while
+	// its result set is open.
+	queryCtx    context.Context
+	cancelQuery context.CancelFunc
+	// result is the latest query's result set, which may have rows left to
+	// fetch.
+	result *database.QueryResult
+
+	// manualTx is set while statements run in explicit transactions, which
+	// the first statement opens and which stay open until committed or
+	// rolled back. txStatements counts the statements run in the open one.
+	manualTx     bool
+	txOpen       bool
+	txStatements int
+}
+
+// Transaction describes the transaction state of a session.
+type Transaction struct {
+	// Manual reports whether statements run in explicit transactions.
+	Manual bool
+	// Open reports whether a transaction is open, and Statements how many
+	// statements have run in it.
+	Open       bool
+	Statements int
+}
+
+// Transaction returns the session's transaction state.
+func (s *Session) Transaction() Transaction {
+	return Transaction{
+		Manual:     s.manualTx,
+		Open:       s.txOpen,
+		Statements: s.txStatements,
+	}
+}
+
+// SetManualTransactions turns explicit transactions on or off. They cannot
+// be turned off while a transaction is open.
+func (s *Session) SetManualTransactions(manual bool) error {
+	if !manual && s.txOpen {
+		return errors.New("commit or roll back the open transaction first")
+	}
+
+	s.manualTx = manual
+
+	return nil
+}
+
+// BeginStatement opens a transaction if statements run in explicit
+// transactions and none is open, and counts the statement about to run. It
+// must be called after BeginQuery, as the connection cannot begin a
+// transaction while a result set is open.
+func (s *Session) BeginStatement(ctx context.Context) error {
+	if !s.manualTx {
+		return nil
+	}
+
+	if !s.txOpen {
+		err := s.Database.Begin(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.txOpen = true
+		s.txStatements = 0
+	}
+
+	s.txStatements++
+
+	return nil
+}
+
+// EndTransaction commits or rolls back the open transaction. The open result
+// set is closed first, as the connection cannot do anything else while it is
+// read.
+func (s *Session) EndTransaction(ctx context.Context, commit bool) error {
+	if !s.txOpen {
+		return errors.New("no transaction is open")
+	}
+
+	if s.busy {
+		return errors.New("a query is running, cancel it first")
+	}
+
+	s.closeResult()
+
+	// The transaction is over whether or not ending it succeeds, as the
+	// server rolls back transactions that cannot be committed.
+	s.txOpen = false
+	s.txStatements = 0
+
+	if commit {
+		return s.Database.Commit(ctx)
+	}
+
+	return s.Database.Rollback(ctx)
+}
+
+// BeginQuery closes the open result set and returns the context for a new
+// query. It reports false if the session is busy.
+func (s *Session) BeginQuery() (context.Context, bool) {
+	if s.busy {
+		return nil, false
+	}
+
+	s.closeResult()
+
+	s.queryCtx, s.cancelQuery = context.WithCancel(context.Background())
+	s.busy = true
+
+	return s.queryCtx, true
+}
+
+// BeginLoad returns the context for loading metadata, such as the catalog,
+// without closing the open result set. It reports false if the session is
+// not idle, as the connection cannot run anything else while rows are read.
+func (s *Session) BeginLoad() (context.Context, bool) {
+	if !s.Idle() {
+		return nil, false
+	}
+
+	s.queryCtx, s.cancelQuery = context.WithCancel(context.Background())
+	s.busy = true
+
+	return s.queryCtx, true
+}
+
+// BeginFetch returns the open result set, and the context of the query that
+// produced it, so more of its rows can be fetched. It reports false if the
+// session is busy or there are no more rows.
+func (s *Session) BeginFetch() (context.Context, *database.QueryResult, bool) {
+	if s.busy || s.result == nil || !s.result.HasMore {
+		return nil, nil, false
+	}
+
+	s.busy = true
+
+	return s.queryCtx, s.result, true
+}
+
+// EndQuery marks the running query or fetch as finished. result is the open
+// result set, or nil if the query or fetch failed.
+func (s *Session) EndQuery(result *database.QueryResult) {
+	s.busy = false
+	if result != nil {
+		s.result = result
+	}
+
+	if result == nil || !result.HasMore {
+		s.closeResult()
+	}
+}
+
+// CancelQuery cancels the running query or fetch. It reports false if the
+// session is not busy.
+func (s *Session) CancelQuery() bool {
+	if !s.busy {
+		return false
+	}
+
+	s.cancelQuery()
+
+	return true
+}
+
+// Running reports whether a query or fetch is in flight.
+func (s *Session) Running() bool {
+	return s.busy
+}
+
+// Idle reports whether the session neither runs a query nor has a result set
+// with rows left to fetch.
+func (s *Session) Idle() bool {
+	return !s.busy && s.result == nil
+}
+
+// closeResult cancels the latest query and releases its result set.
+// Cancelling first stops the server from sending rows that would otherwise
+// have to be drained. Inside a transaction the rows are drained instead, as
+// cancelling would end the transaction too: Postgres aborts it, and the MySQL
+// driver closes the connection it runs on.
+func (s *Session) closeResult() {
+	if s.txOpen && s.result != nil && !s.busy {
+		_ = s.result.Close()
+		s.result = nil
+	}
+
+	if s.cancelQuery != nil {
+		s.cancelQuery()
+		s.queryCtx, s.cancelQuery = nil, nil
+	}
+
+	// A running query or fetch owns the result set until it finishes.
+	if s.result != nil && !s.busy {
+		_ = s.result.Close()
+	}
+
+	s.result = nil
+}
+
+// Manager keeps track of the open sessions and which one is active.
+type Manager struct {
+	sessions []*Session
+	active   int
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		active: -1,
+	}
+}
+
+// Open adds a connected session and makes it the active one.
+func (m *Manager) Open(name string, cfg config.ConnectionConfig, db database.DatabaseIntegration) *Session {
+	s := &Session{
+		Name:     name,
+		Config:   cfg,
+		Database: db,
+	}
+
+	m.sessions = append(m.sessions, s)
+	m.active = len(m.sessions) - 1
+
+	return s
+}
+
+// Get returns the open session with the given name.
+func (m *Manager) Get(name string) (*Session, bool) {
+	i := m.index(name)
+	if i < 0 {
+		return nil, false
+	}
+
+	return m.sessions[i], true
+}
+
+// Active returns the active session, or nil when no session is open.
+func (m *Manager) Active() *Session {
+	if m.active < 0 {
+		return nil
+	}
+
+	return m.sessions[m.active]
+}
+
+// Activate makes the named session the active one.
+func (m *Manager) Activate(name string) error {
+	i := m.index(name)
+	if i < 0 {
+		return fmt.Errorf("session not open: %s", name)
+	}
+
+	m.active = i
+
+	return nil
+}
+
+// Next activates the session after the active one, wrapping around.
+func (m *Manager) Next() *Session {
+	if len(m.sessions) == 0 {
+		return nil
+	}
+
+	m.active = (m.active + 1) % len(m.sessions)
+
+	return m.sessions[m.active]
+}
+
+// Close closes the named session's connection and removes it. If it was the
+// active session, the previous session becomes active.
+func (m *Manager) Close(name string) error {
+	i := m.index(name)
+	if i < 0 {
+		return fmt.Errorf("session not open: %s", name)
+	}
+
+	m.sessions[i].closeResult()
+	err := m.sessions[i].Database.Close()
+
+	m.sessions = slices.Delete(m.sessions, i, i+1)
+	if m.active >= i {
+		m.active--
+	}
+	if m.active < 0 && len(m.sessions) > 0 {
+		m.active = 0
+	}
+
+	return err
+}
+
+// CloseAll closes every open session.
+func (m *Manager) CloseAll() error {
+	var errs []error
+	for _, s := range m.sessions {
+		s.closeResult()
+		errs = append(errs, s.Database.Close())
+	}
+
+	m.sessions = nil
+	m.active = -1
+
+	return errors.Join(errs...)
+}
+
+// Names returns the names of the open sessions in the order they were opened.
+func (m *Manager) Names() []string {
+	names := make([]string, len(m.sessions))
+	for i, s := range m.sessions {
+		names[i] = s.Name
+	}
+
+	return names
+}
+
+// OpenTransactions returns the names of the sessions with an open
+// transaction.
+func (m *Manager) OpenTransactions() []string {
+	var names []string
+	for _, s := range m.sessions {
+		if s.txOpen {
+			names = append(names, s.Name)
+		}
+	}
+
+	return names
+}
+
+// ActiveName returns the name of the active session, or "" when none is open.
+func (m *Manager) ActiveName() string {
+	if s := m.Active(); s != nil {
+		return s.Name
+	}
+
+	return ""
+}
+
+func (m *Manager) index(name string) int {
+	return slices.IndexFunc(m.sessions, func(s *Session) bool {
+		return s.Name == name
+	})
+}
@@ -0,0 +1,175 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davesavic/lazydb/internal/service/config"
+	"github.com/davesavic/lazydb/internal/service/database"
+)
+
+// fakeDatabase is a database whose transactions only record that they are
+// open. Methods the tests do not use are left to the nil interface.
+type fakeDatabase struct {
+	database.DatabaseIntegration
+	txOpen bool
+}
+
+func (f *fakeDatabase) Begin(context.Context) error {
+	f.txOpen = true
+	return nil
+}
+
+func (f *fakeDatabase) Commit(context.Context) error {
+	f.txOpen = false
+	return nil
+}
+
+func (f *fakeDatabase) Rollback(context.Context) error {
+	f.txOpen = false
+	return nil
+}
+
+// endlessRows is a result set that always has more rows, and records whether
+// the query's context was cancelled before it was closed.
+type endlessRows struct {
+	ctx             context.Context
+	closed          bool
+	cancelledBefore bool
+}
+
+func (r *endlessRows) Next(n int) ([][]any, error) {
+	return make([][]any, n), nil
+}
+
+func (r *endlessRows) Close() error {
+	if !r.closed {
+		r.closed = true
+		r.cancelledBefore = r.ctx.Err() != nil
+	}
+
+	return nil
+}
+
+// runPartial runs a query on s that leaves rows to fetch.
+func runPartial(t *testing.T, s *Session) *endlessRows {
+	t.Helper()
+
+	ctx, ok := s.BeginQuery()
+	if !ok {
+		t.Fatal("session is busy")
+	}
+
+	err := s.BeginStatement(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := &endlessRows{ctx: ctx}
+	result := database.NewQueryResult(nil, rows)
+	err = result.Fetch(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.EndQuery(result)
+
+	return rows
+}
+
+func TestCloseResultKeepsTransaction(t *testing.T) {
+	db := &fakeDatabase{}
+	s := NewManager().Open("test", config.ConnectionConfig{}, db)
+
+	err := s.SetManualTransactions(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := runPartial(t, s)
+	second := runPartial(t, s)
+
+	if !first.closed {
+		t.Fatal("first result set was not closed by the second query")
+	}
+	if first.cancelledBefore {
+		t.Error("first query was cancelled inside the transaction")
+	}
+	if !db.txOpen || !s.Transaction().Open || s.Transaction().Statements != 2 {
+		t.Fatalf("transaction %+v, want 2 statements in an open transaction", s.Transaction())
+	}
+
+	err = s.EndTransaction(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.closed || second.cancelledBefore {
+		t.Errorf("second result set closed %v, cancelled before %v, want closed without cancelling", second.closed, second.cancelledBefore)
+	}
+	if db.txOpen {
+		t.Error("transaction was not committed")
+	}
+}
+
+func TestCloseResultCancelsOutsideTransaction(t *testing.T) {
+	s := NewManager().Open("test", config.ConnectionConfig{}, &fakeDatabase{})
+
+	first := runPartial(t, s)
+	runPartial(t, s)
+
+	if !first.closed || !first.cancelledBefore {
+		t.Errorf("first result set closed %v, cancelled before %v, want cancelled then closed", first.closed, first.cancelledBefore)
+	}
+}
+
+func TestLoadWaitsForOpenResult(t *testing.T) {
+	s := NewManager().Open("test", config.ConnectionConfig{}, &fakeDatabase{})
+
+	ctx, ok := s.BeginLoad()
+	if !ok {
+		t.Fatal("idle session cannot load")
+	}
+	s.EndQuery(nil)
+	if ctx.Err() == nil {
+		t.Error("load context is alive after the load ended")
+	}
+
+	rows := runPartial(t, s)
+
+	_, ok = s.BeginLoad()
+	if ok {
+		t.Fatal("load began while a result set is open")
+	}
+	if s.Idle() || s.Running() {
+		t.Errorf("idle %v, running %v, want an open result set and nothing running", s.Idle(), s.Running())
+	}
+	if rows.closed || rows.ctx.Err() != nil {
+		t.Fatalf("result set closed %v, cancelled %v, want it left open", rows.closed, rows.ctx.Err() != nil)
+	}
+
+	// The rows can still be fetched.
+	fetchCtx, result, ok := s.BeginFetch()
+	if !ok || fetchCtx != rows.ctx {
+		t.Fatal("cannot fetch more rows of the open result set")
+	}
+	err := result.Fetch(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.EndQuery(result)
+
+	// Once the next query is done with its rows, the load can begin.
+	_, ok = s.BeginQuery()
+	if !ok {
+		t.Fatal("session is busy")
+	}
+	s.EndQuery(nil)
+	if !rows.closed || !s.Idle() {
+		t.Fatalf("result set closed %v, idle %v, want closed and idle", rows.closed, s.Idle())
+	}
+
+	_, ok = s.BeginLoad()
+	if !ok {
+		t.Error("load cannot begin once the result set is closed")
+	}
+}
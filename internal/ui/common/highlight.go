@@ -0,0 +1,21 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/quick"
+)
+
+// Highlight colours source written in language, e.g. "json" or "xml", for
+// display in the terminal. The source is returned as is if it cannot be
+// highlighted.
+func Highlight(source, language string) string {
+	var b strings.Builder
+
+	err := quick.Highlight(&b, source, language, "terminal256", "monokai")
+	if err != nil {
+		return source
+	}
+
+	return b.String()
+}
@@ -3,13 +3,16 @@ package common
 import (
 	"github.com/davesavic/lazydb/internal/keybinding"
 	"github.com/davesavic/lazydb/internal/service/config"
-	"github.com/davesavic/lazydb/internal/service/database"
+	"github.com/davesavic/lazydb/internal/service/history"
 	"github.com/davesavic/lazydb/internal/service/message"
+	"github.com/davesavic/lazydb/internal/service/session"
 )
 
 type ScreenProps struct {
-	MessageManager  *message.Manager
-	ConfigService   *config.Service
-	DatabaseService database.DatabaseIntegration
-	Keymap          *keybinding.Keymap
+	MessageManager *message.Manager
+	ConfigService  *config.Service
+	SessionManager *session.Manager
+	History        *history.Store
+	Params         *history.ParamStore
+	Keymap         *keybinding.Keymap
 }
@@ -2,6 +2,7 @@ package connection
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -24,10 +25,19 @@ type Model struct {
 type listItem struct {
 	name        string
 	description string
+	open        bool
+	active      bool
 }
 
 func (l listItem) Title() string {
-	return l.name
+	switch {
+	case l.active:
+		return "● " + l.name
+	case l.open:
+		return "○ " + l.name
+	default:
+		return l.name
+	}
 }
 
 func (l listItem) Description() string {
@@ -43,7 +53,12 @@ func (m *Model) Init() tea.Cmd {
 	consCfg, _ := m.screenProps.ConfigService.LoadConnections("connections.toml")
 	items := make([]list.Item, 0, len(consCfg.Connections))
 	for name, c := range consCfg.Connections {
-		items = append(items, listItem{name: name, description: fmt.Sprintf("%s - %s:%s", c.Type, c.Host, c.Port)})
+		description := fmt.Sprintf("%s - %s:%s", c.Type, c.Host, c.Port)
+		if c.Path != "" {
+			description = fmt.Sprintf("%s - %s", c.Type, c.Path)
+		}
+
+		items = append(items, listItem{name: name, description: description})
 	}
 	m.list.SetItems(items)
 	m.list.SetShowStatusBar(false)
@@ -56,10 +71,26 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case message.SessionsChangedMsg:
+		m.markSessions(msg)
+
 	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+
 		switch {
 		case msg.String() == "q":
 			return m, nil
+		case key.Matches(msg, m.screenProps.Keymap.CloseConnection):
+			selected := m.list.SelectedItem()
+			if selected == nil {
+				return m, nil
+			}
+
+			cmds = append(cmds, m.screenProps.MessageManager.NewCloseConnectionCmd(message.CloseConnectionMsg{
+				Name: selected.FilterValue(),
+			}))
 		case msg.String() == "enter":
 			selected := m.list.SelectedItem()
 			if selected == nil {
@@ -111,6 +142,23 @@ func NewModel(props *common.ScreenProps) *Model {
 	}
 }
 
+// markSessions flags the connections that have an open session.
+func (m *Model) markSessions(msg message.SessionsChangedMsg) {
+	items := m.list.Items()
+	for i, item := range items {
+		li, ok := item.(listItem)
+		if !ok {
+			continue
+		}
+
+		li.open = slices.Contains(msg.Sessions, li.name)
+		li.active = li.name == msg.Active
+		items[i] = li
+	}
+
+	m.list.SetItems(items)
+}
+
 func (m *Model) SetSize(width, height int) {
 	m.width = width
 	m.height = height
@@ -0,0 +1,361 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/davesavic/lazydb/internal/service/database"
+	"github.com/davesavic/lazydb/internal/service/message"
+)
+
+const (
+	// maxCompletions is the number of names the popup offers at most, and
+	// visibleCompletions the number it shows at once.
+	maxCompletions     = 50
+	visibleCompletions = 8
+	// maxCompletionWidth is the width names are cut to in the popup.
+	maxCompletionWidth = 40
+)
+
+var (
+	sqlKeywords = []string{
+		"SELECT", "FROM", "WHERE", "AND", "OR", "NOT", "IN", "IS", "NULL", "LIKE", "ILIKE",
+		"BETWEEN", "EXISTS", "AS", "DISTINCT", "JOIN", "INNER", "LEFT", "RIGHT", "FULL",
+		"OUTER", "CROSS", "ON", "USING", "GROUP", "BY", "ORDER", "ASC", "DESC", "HAVING",
+		"LIMIT", "OFFSET", "UNION", "ALL", "EXCEPT", "INTERSECT", "CASE", "WHEN", "THEN",
+		"ELSE", "END", "INSERT", "INTO", "VALUES", "UPDATE", "SET", "DELETE", "RETURNING",
+		"CREATE", "ALTER", "DROP", "TABLE", "VIEW", "INDEX", "SCHEMA", "PRIMARY", "KEY",
+		"FOREIGN", "REFERENCES", "UNIQUE", "DEFAULT", "CHECK", "CONSTRAINT", "WITH",
+		"RECURSIVE", "BEGIN", "COMMIT", "ROLLBACK", "EXPLAIN", "ANALYZE", "TRUNCATE",
+		"TRUE", "FALSE", "CAST", "OVER", "PARTITION", "WINDOW",
+	}
+	sqlFunctions = []string{
+		"count", "sum", "avg", "min", "max", "coalesce", "nullif", "greatest", "least",
+		"lower", "upper", "length", "trim", "substring", "replace", "concat", "round",
+		"abs", "floor", "ceil", "now", "current_date", "current_timestamp", "extract",
+		"date_trunc", "row_number", "rank", "dense_rank", "lag", "lead",
+	}
+	// tableKeywords are followed by the name of a table.
+	tableKeywords = []string{"FROM", "JOIN", "UPDATE", "INTO", "TABLE"}
+
+	// Names matching these patterns are written without quotes.
+	postgresNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+	namePattern         = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+	completionStyle         = lipgloss.NewStyle().Background(lipgloss.Color("237")).Foreground(lipgloss.Color("252"))
+	selectedCompletionStyle = lipgloss.NewStyle().Background(lipgloss.Color("62")).Foreground(lipgloss.Color("230"))
+)
+
+// candidate is a name that completes the word at the cursor.
+type candidate struct {
+	name string
+	// kind is what the name is, e.g. "table" or "keyword".
+	kind string
+	// text is the name as it is inserted, quoted if it needs to be.
+	text string
+}
+
+// completion is the popup of names that complete the word at the cursor.
+type completion struct {
+	// prefix is the part of the word before the cursor.
+	prefix   string
+	items    []candidate
+	selected int
+}
+
+// move selects the item delta items away from the selected one, wrapping
+// around.
+func (c *completion) move(delta int) {
+	c.selected = (c.selected + delta + len(c.items)) % len(c.items)
+}
+
+// lines draws the items in view, which scroll to keep the selected one
+// visible.
+func (c *completion) lines() []string {
+	first := max(c.selected-visibleCompletions+1, 0)
+	items := c.items[first:min(first+visibleCompletions, len(c.items))]
+
+	nameWidth, kindWidth := 0, 0
+	for _, item := range items {
+		nameWidth = max(nameWidth, min(lipgloss.Width(item.name), maxCompletionWidth))
+		kindWidth = max(kindWidth, len(item.kind))
+	}
+
+	lines := make([]string, len(items))
+	for i, item := range items {
+		style := completionStyle
+		if first+i == c.selected {
+			style = selectedCompletionStyle
+		}
+
+		name := ansi.Truncate(item.name, maxCompletionWidth, "…")
+		lines[i] = style.Render(fmt.Sprintf(" %-*s  %-*s ", nameWidth+utf8.RuneCountInString(name)-lipgloss.Width(name), name, kindWidth, item.kind))
+	}
+
+	return lines
+}
+
+// updateCompletion opens, filters or closes the completion popup for the
+// word at the cursor. Without force, the popup is only opened once part of a
+// name has been typed.
+func (m *Model) updateCompletion(force bool) {
+	value := m.textarea.Value()
+	cursor := m.cursorOffset()
+
+	start := cursor
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(value[:start])
+		if !isNameRune(r) {
+			break
+		}
+		start -= size
+	}
+	prefix := value[start:cursor]
+
+	before := start
+	qualifier := ""
+	if start > 0 && value[start-1] == '.' {
+		qualifier, before = nameBefore(value, start-1)
+	}
+
+	if !force && prefix == "" && qualifier == "" {
+		m.completion = nil
+		return
+	}
+
+	items := m.candidates(value, cursor, qualifier, prefix, previousWord(value[:before]))
+
+	// A name that is typed in full needs no popup.
+	if len(items) == 0 || !force && len(items) == 1 && items[0].text == prefix {
+		m.completion = nil
+		return
+	}
+
+	selected := 0
+	if m.completion != nil && m.completion.selected < len(m.completion.items) {
+		selected = max(slices.IndexFunc(items, func(c candidate) bool {
+			return c == m.completion.items[m.completion.selected]
+		}), 0)
+	}
+
+	m.completion = &completion{
+		prefix:   prefix,
+		items:    items,
+		selected: selected,
+	}
+}
+
+// candidates returns the names that complete prefix, qualified by qualifier
+// if it is not empty, for the statement around the cursor. previous is the
+// word before the name, which tells whether a table name is expected.
+func (m *Model) candidates(value string, cursor int, qualifier, prefix, previous string) []candidate {
+	dialect := m.dialect()
+
+	var refs []database.TableRef
+	for _, span := range dialect.Split(value) {
+		if span.Start <= cursor && cursor <= span.End {
+			refs = dialect.References(value[span.Start:span.End])
+		}
+	}
+
+	var items []candidate
+	seen := make(map[candidate]bool)
+	add := func(kind string, names ...string) {
+		for _, name := range names {
+			if len(items) >= maxCompletions || !strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+				continue
+			}
+
+			c := candidate{name: name, kind: kind, text: name}
+			switch kind {
+			case "keyword":
+				if prefix != "" && strings.ToLower(prefix) == prefix {
+					c.text = strings.ToLower(name)
+				}
+			case "function":
+			default:
+				c.text = quoteName(dialect, name)
+			}
+
+			if !seen[c] {
+				seen[c] = true
+				items = append(items, c)
+			}
+		}
+	}
+
+	if qualifier != "" {
+		for _, ref := range refs {
+			if strings.EqualFold(ref.Alias, qualifier) || ref.Alias == "" && strings.EqualFold(ref.Name, qualifier) {
+				if t := m.catalogTable(ref.Schema, ref.Name); t != nil {
+					add("column", t.Columns...)
+				}
+			}
+		}
+
+		if m.catalog != nil {
+			for _, t := range m.catalog.Tables {
+				if strings.EqualFold(t.Schema, qualifier) {
+					add("table", t.Name)
+				}
+			}
+		}
+
+		if t := m.catalogTable("", qualifier); len(items) == 0 && t != nil {
+			add("column", t.Columns...)
+		}
+
+		return items
+	}
+
+	if slices.Contains(tableKeywords, previous) {
+		m.addTables(add)
+		return items
+	}
+
+	for _, ref := range refs {
+		if t := m.catalogTable(ref.Schema, ref.Name); t != nil {
+			add("column", t.Columns...)
+		}
+	}
+	if m.catalog != nil {
+		add("function", m.catalog.Functions...)
+	}
+	add("function", sqlFunctions...)
+	add("keyword", sqlKeywords...)
+	m.addTables(add)
+
+	return items
+}
+
+// addTables adds the tables and schemas of the catalog as candidates.
+func (m *Model) addTables(add func(kind string, names ...string)) {
+	if m.catalog == nil {
+		return
+	}
+
+	for _, t := range m.catalog.Tables {
+		add("table", t.Name)
+	}
+	add("schema", m.catalog.Schemas...)
+}
+
+// catalogTable returns the table of the catalog with the given name, in the
+// given schema unless it is empty.
+func (m *Model) catalogTable(schema, name string) *database.CatalogTable {
+	if m.catalog == nil {
+		return nil
+	}
+
+	for i, t := range m.catalog.Tables {
+		if strings.EqualFold(t.Name, name) && (schema == "" || strings.EqualFold(t.Schema, schema)) {
+			return &m.catalog.Tables[i]
+		}
+	}
+
+	return nil
+}
+
+// acceptCompletion replaces the part of the word before the cursor with the
+// selected name.
+func (m *Model) acceptCompletion() {
+	item := m.completion.items[m.completion.selected]
+
+	for range utf8.RuneCountInString(m.completion.prefix) {
+		m.textarea, _ = m.textarea.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	m.textarea.InsertString(item.text)
+
+	m.completion = nil
+	m.reposition()
+}
+
+// reloadCompletions loads the names of the active session again, such as
+// after tables were created.
+func (m *Model) reloadCompletions() tea.Cmd {
+	name := m.screenProps.SessionManager.ActiveName()
+	if name == "" {
+		return message.NewStatusUpdateCmd("NO CONNECTION", "Connect to a database to complete its names")
+	}
+
+	return m.screenProps.MessageManager.NewLoadCatalogCmd(name)
+}
+
+// overlayCompletion draws the completion popup over the rows of the editor,
+// below the row of the cursor or above it if there is no room below. x is the
+// column the word being completed starts at.
+func (m *Model) overlayCompletion(rows []string, row, x int) {
+	lines := m.completion.lines()
+	width := lipgloss.Width(lines[0])
+	x = max(min(x, m.width-width), 0)
+
+	top := row + 1
+	if top+len(lines) > len(rows) && row >= len(lines) {
+		top = row - len(lines)
+	}
+	lines = lines[:min(len(lines), len(rows)-top)]
+
+	for i, line := range lines {
+		r := rows[top+i]
+		left := ansi.Truncate(r, x, "")
+		left += strings.Repeat(" ", x-ansi.StringWidth(left))
+		rows[top+i] = left + line + ansi.TruncateLeft(r, x+width, "")
+	}
+}
+
+// quoteName quotes the name of a table, column or schema if it cannot be
+// written without quotes.
+func quoteName(dialect database.Dialect, name string) string {
+	pattern := namePattern
+	if dialect == database.DialectPostgres {
+		pattern = postgresNamePattern
+	}
+
+	if pattern.MatchString(name) {
+		return name
+	}
+
+	return dialect.QuoteIdentifier(name)
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// nameBefore returns the name that ends just before offset end of s, without
+// its quotes if it is quoted, and the offset it starts at.
+func nameBefore(s string, end int) (string, int) {
+	if end > 0 && (s[end-1] == '"' || s[end-1] == '`') {
+		open := strings.LastIndexByte(s[:end-1], s[end-1])
+		if open >= 0 {
+			return s[open+1 : end-1], open
+		}
+	}
+
+	start := end
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:start])
+		if !isNameRune(r) {
+			break
+		}
+		start -= size
+	}
+
+	return s[start:end], start
+}
+
+// previousWord returns the word that s ends with, ignoring trailing
+// whitespace, in upper case.
+func previousWord(s string) string {
+	s = strings.TrimRightFunc(s, unicode.IsSpace)
+	word, _ := nameBefore(s, len(s))
+
+	return strings.ToUpper(word)
+}
@@ -0,0 +1,327 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	tokenStyles = map[TokenKind]lipgloss.Style{
+		TokenKeyword:    lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Bold(true),
+		TokenString:     lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		TokenNumber:     lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		TokenComment:    lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Italic(true),
+		TokenIdentifier: lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
+		TokenOperator:   lipgloss.NewStyle().Foreground(lipgloss.Color("5")),
+	}
+	currentLineStyle  = lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "254", Dark: "236"})
+	bracketMatchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true).Underline(true)
+	selectedStyle     = lipgloss.NewStyle().Reverse(true)
+)
+
+// glyph is how a character of the buffer is drawn.
+type glyph struct {
+	kind     TokenKind
+	matched  bool
+	selected bool
+}
+
+// style returns the style of the glyph on a line drawn in line.
+func (g glyph) style(line lipgloss.Style) lipgloss.Style {
+	style := line
+	if s, ok := tokenStyles[g.kind]; ok {
+		style = s.Inherit(style)
+	}
+	if g.matched {
+		style = bracketMatchStyle.Inherit(style)
+	}
+	if g.selected {
+		style = selectedStyle.Inherit(style)
+	}
+
+	return style
+}
+
+// tokenKinds returns the kind of token each byte of value belongs to, as
+// highlighted for the driver of the active session.
+func (m *Model) tokenKinds(value string) []TokenKind {
+	driver := ""
+	if s := m.screenProps.SessionManager.Active(); s != nil {
+		driver = s.Config.Type
+	}
+
+	if value == m.highlighted && driver == m.highlightedFor {
+		return m.kinds
+	}
+
+	kinds := make([]TokenKind, len(value))
+	for _, t := range highlighterFor(driver)(value) {
+		for i := max(t.Start, 0); i < min(t.End, len(value)); i++ {
+			kinds[i] = t.Kind
+		}
+	}
+
+	m.highlighted, m.highlightedFor, m.kinds = value, driver, kinds
+
+	return kinds
+}
+
+// editorView draws the buffer with its tokens highlighted, the cursor line
+// highlighted, and the bracket at the cursor and its match marked. Lines are
+// wrapped and scrolled the way the textarea does, so that the cursor is where
+// the textarea edits.
+func (m *Model) editorView() string {
+	ta := &m.textarea
+
+	style := ta.BlurredStyle
+	if ta.Focused() {
+		style = ta.FocusedStyle
+	}
+
+	value := ta.Value()
+	kinds := m.tokenKinds(value)
+
+	bracket, match := matchBrackets(value, kinds, m.cursorOffset())
+	selStart, selEnd := -1, -1
+	if m.mark >= 0 {
+		selStart, selEnd = m.selection()
+	}
+
+	info := ta.LineInfo()
+	width := ta.Width()
+	digits := len(strconv.Itoa(ta.MaxHeight))
+
+	var rows []string
+	cursorRow, cursorX := 0, 0
+	offset := 0
+	for l, line := range strings.Split(value, "\n") {
+		lineStyle, numberStyle := style.Text, style.LineNumber
+		if l == ta.Line() {
+			lineStyle, numberStyle = style.CursorLine, style.CursorLineNumber
+		}
+
+		runes := []rune(line)
+		glyphs := make([]glyph, len(runes)+1)
+		pos := offset
+		for i, r := range runes {
+			glyphs[i] = glyph{
+				kind:     kinds[pos],
+				matched:  pos == bracket || pos == match,
+				selected: pos >= selStart && pos < selEnd,
+			}
+			pos += utf8.RuneLen(r)
+		}
+
+		// The wrapped lines hold the runes of the line in order, followed by
+		// a space for the cursor at the end of the line.
+		i := 0
+		for wl, wrapped := range wrap(runes, width) {
+			var s strings.Builder
+			s.WriteString(lineStyle.Render(style.Prompt.Render(ta.Prompt)))
+
+			if ta.ShowLineNumbers {
+				number := " "
+				if wl == 0 {
+					number = strconv.Itoa(l + 1)
+				}
+				s.WriteString(lineStyle.Render(numberStyle.Render(fmt.Sprintf(" %*v ", digits, number))))
+			}
+
+			n := len(wrapped)
+			padding := width - lipgloss.Width(string(wrapped))
+			if padding < 0 {
+				wrapped = []rune(strings.TrimSuffix(string(wrapped), " "))
+				padding = 0
+			}
+
+			col := -1
+			if l == ta.Line() && wl == info.RowOffset {
+				col = info.ColumnOffset
+				cursorRow, cursorX = len(rows), lipgloss.Width(s.String())+info.CharOffset
+			}
+
+			s.WriteString(renderGlyphs(ta.Cursor, wrapped, glyphs[min(i, len(glyphs)):], col, lineStyle))
+			s.WriteString(lineStyle.Render(strings.Repeat(" ", padding)))
+
+			rows = append(rows, s.String())
+			i += n
+		}
+
+		offset += len(line) + 1
+	}
+
+	for len(rows) < m.top+ta.Height() {
+		rows = append(rows, style.Prompt.Render(ta.Prompt)+style.EndOfBuffer.Render(string(ta.EndOfBufferCharacter)))
+	}
+
+	rows = rows[m.top : m.top+ta.Height()]
+	if m.completion != nil {
+		m.overlayCompletion(rows, cursorRow-m.top, cursorX-lipgloss.Width(m.completion.prefix))
+	}
+
+	return style.Base.Render(strings.Join(rows, "\n"))
+}
+
+// renderGlyphs draws a wrapped line, styling runs of runes that look the
+// same at once and drawing the cursor c at column col, if it is not -1.
+func renderGlyphs(c cursor.Model, runes []rune, glyphs []glyph, col int, line lipgloss.Style) string {
+	var s strings.Builder
+	at := func(i int) glyph {
+		if i < len(glyphs) {
+			return glyphs[i]
+		}
+		return glyph{}
+	}
+
+	for start := 0; start < len(runes); {
+		if start == col {
+			c.TextStyle = at(start).style(line)
+			c.SetChar(string(runes[start]))
+			s.WriteString(c.View())
+			start++
+			continue
+		}
+
+		end := start + 1
+		for end < len(runes) && end != col && at(end) == at(start) {
+			end++
+		}
+
+		s.WriteString(at(start).style(line).Render(string(runes[start:end])))
+		start = end
+	}
+
+	// At the end of a full line the cursor is drawn past it.
+	if col >= len(runes) {
+		c.TextStyle = line
+		c.SetChar(" ")
+		s.WriteString(c.View())
+	}
+
+	return s.String()
+}
+
+// reposition scrolls the editor so that the cursor is in view.
+func (m *Model) reposition() {
+	width := m.textarea.Width()
+	lines := strings.Split(m.textarea.Value(), "\n")
+
+	row, total := 0, 0
+	for l, line := range lines {
+		if l == m.textarea.Line() {
+			row = total + m.textarea.LineInfo().RowOffset
+		}
+		total += len(wrap([]rune(line), width))
+	}
+
+	height := m.textarea.Height()
+	switch {
+	case row < m.top:
+		m.top = row
+	case row >= m.top+height:
+		m.top = row - height + 1
+	}
+	m.top = max(min(m.top, total-1), 0)
+}
+
+// matchBrackets returns the offsets of the bracket at or just before the
+// cursor and the bracket that matches it, or -1 for both if there is no
+// matching pair. Brackets in strings and comments are ignored.
+func matchBrackets(value string, kinds []TokenKind, cursor int) (int, int) {
+	isBracket := func(i int) bool {
+		return i >= 0 && i < len(value) && strings.IndexByte("()[]{}", value[i]) >= 0 &&
+			kinds[i] != TokenString && kinds[i] != TokenComment
+	}
+
+	at := cursor
+	if !isBracket(at) {
+		at--
+	}
+	if !isBracket(at) {
+		return -1, -1
+	}
+
+	const pairs = "()[]{}"
+	i := strings.IndexByte(pairs, value[at])
+	opening, closing := pairs[i&^1], pairs[i|1]
+
+	step := 1
+	if value[at] == closing {
+		step = -1
+	}
+
+	depth := 0
+	for j := at; j >= 0 && j < len(value); j += step {
+		if !isBracket(j) {
+			continue
+		}
+
+		switch value[j] {
+		case opening:
+			depth += step
+		case closing:
+			depth -= step
+		}
+
+		if depth == 0 {
+			return min(at, j), max(at, j)
+		}
+	}
+
+	return -1, -1
+}
+
+// wrap soft wraps a line the way the textarea does: at spaces where
+// possible, and within words that are wider than the line. A space is added
+// to the end of the last wrapped line for the cursor.
+func wrap(runes []rune, width int) [][]rune {
+	var (
+		lines  = [][]rune{{}}
+		word   []rune
+		row    int
+		spaces int
+	)
+
+	for _, r := range runes {
+		if unicode.IsSpace(r) {
+			spaces++
+		} else {
+			word = append(word, r)
+		}
+
+		if spaces > 0 {
+			if lipgloss.Width(string(lines[row]))+lipgloss.Width(string(word))+spaces > width {
+				row++
+				lines = append(lines, []rune{})
+			}
+			lines[row] = append(lines[row], word...)
+			lines[row] = append(lines[row], []rune(strings.Repeat(" ", spaces))...)
+			spaces = 0
+			word = nil
+		} else if lipgloss.Width(string(word))+lipgloss.Width(string(word[len(word)-1])) > width {
+			// A double width rune at the end of the word may not fit.
+			if len(lines[row]) > 0 {
+				row++
+				lines = append(lines, []rune{})
+			}
+			lines[row] = append(lines[row], word...)
+			word = nil
+		}
+	}
+
+	spaces++
+	if lipgloss.Width(string(lines[row]))+lipgloss.Width(string(word))+spaces >= width {
+		lines = append(lines, []rune{})
+		row++
+	}
+	lines[row] = append(lines[row], word...)
+	lines[row] = append(lines[row], []rune(strings.Repeat(" ", spaces))...)
+
+	return lines
+}
@@ -0,0 +1,127 @@
+package query
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/davesavic/lazydb/internal/service/database"
+)
+
+// TokenKind is what a highlighted token is, which decides its colour.
+type TokenKind int
+
+const (
+	// TokenText is anything that is not highlighted, such as whitespace and
+	// punctuation.
+	TokenText TokenKind = iota
+	TokenKeyword
+	TokenString
+	TokenNumber
+	TokenComment
+	TokenIdentifier
+	TokenOperator
+)
+
+// Token is a highlighted part of a query, as byte offsets.
+type Token struct {
+	Kind  TokenKind
+	Start int
+	End   int
+}
+
+// Highlighter splits a query into the tokens to highlight. Parts of the
+// query that are not covered by a token are shown as text.
+type Highlighter func(query string) []Token
+
+var (
+	highlightersMu sync.RWMutex
+	highlighters   = make(map[string]Highlighter)
+
+	// defaultHighlighter highlights queries for drivers without a highlighter
+	// of their own, such as plugins.
+	defaultHighlighter = ChromaHighlighter("sql")
+)
+
+func init() {
+	RegisterHighlighter(database.PostgresDriverName, ChromaHighlighter("postgresql"))
+	RegisterHighlighter(database.MySQLDriverName, ChromaHighlighter("mysql"))
+	RegisterHighlighter(database.SQLiteDriverName, ChromaHighlighter("sql"))
+}
+
+// RegisterHighlighter makes h highlight the queries of the driver with the
+// given type name, replacing any highlighter registered before.
+func RegisterHighlighter(driver string, h Highlighter) {
+	highlightersMu.Lock()
+	defer highlightersMu.Unlock()
+
+	highlighters[strings.ToLower(driver)] = h
+}
+
+// highlighterFor returns the highlighter for the driver with the given type
+// name.
+func highlighterFor(driver string) Highlighter {
+	highlightersMu.RLock()
+	defer highlightersMu.RUnlock()
+
+	if h, ok := highlighters[strings.ToLower(driver)]; ok {
+		return h
+	}
+
+	return defaultHighlighter
+}
+
+// ChromaHighlighter highlights queries with the chroma lexer of the given
+// name, e.g. "postgresql".
+func ChromaHighlighter(lexer string) Highlighter {
+	l := lexers.Get(lexer)
+	if l == nil {
+		l = lexers.Fallback
+	}
+	l = chroma.Coalesce(l)
+
+	return func(query string) []Token {
+		it, err := l.Tokenise(&chroma.TokeniseOptions{State: "root"}, query)
+		if err != nil {
+			return nil
+		}
+
+		var tokens []Token
+		pos := 0
+		for t := it(); t != chroma.EOF; t = it() {
+			start := pos
+			pos += len(t.Value)
+
+			// Lexers may add a newline to the end of the query.
+			if start >= len(query) {
+				break
+			}
+
+			if kind := chromaKind(t.Type); kind != TokenText {
+				tokens = append(tokens, Token{Kind: kind, Start: start, End: min(pos, len(query))})
+			}
+		}
+
+		return tokens
+	}
+}
+
+func chromaKind(t chroma.TokenType) TokenKind {
+	switch {
+	case t.InCategory(chroma.Keyword):
+		return TokenKeyword
+	case t.InSubCategory(chroma.LiteralString):
+		return TokenString
+	case t.InSubCategory(chroma.LiteralNumber):
+		return TokenNumber
+	case t.InCategory(chroma.Comment):
+		return TokenComment
+	case t.InCategory(chroma.Name):
+		return TokenIdentifier
+	case t.InCategory(chroma.Operator):
+		return TokenOperator
+	}
+
+	return TokenText
+}
@@ -1,10 +1,16 @@
 package query
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/database"
 	"github.com/davesavic/lazydb/internal/service/message"
 	"github.com/davesavic/lazydb/internal/ui/common"
 )
@@ -18,17 +24,45 @@ type Model struct {
 	height      int
 
 	textarea textarea.Model
+	// mark is the byte offset in the buffer where the selection starts, or
+	// -1 if nothing is selected. The selection ends at the cursor.
+	mark int
+	// ran holds the statements of the latest run and where they started in
+	// the buffer, to find the position of an error in one of them.
+	ran []ranStatement
+
+	// catalog holds the names of the session's database, and completion the
+	// popup of names that complete the word at the cursor while it is open.
+	catalog    *database.Catalog
+	completion *completion
+
+	// top is the first wrapped line in view.
+	top int
+	// kinds holds the token kind of each byte of the buffer, as highlighted
+	// for the driver highlightedFor when the buffer was highlighted.
+	highlighted    string
+	highlightedFor string
+	kinds          []TokenKind
+}
+
+// ranStatement is a statement that was run from the buffer.
+type ranStatement struct {
+	query   string
+	start   int
+	dialect database.Dialect
 }
 
 func NewModel(props *common.ScreenProps) *Model {
 	textareaModel := textarea.New()
 	textareaModel.Reset()
 	textareaModel.SetCursor(0)
+	textareaModel.FocusedStyle.CursorLine = currentLineStyle
 
 	return &Model{
 		id:          "query",
 		screenProps: props,
 		textarea:    textareaModel,
+		mark:        -1,
 	}
 }
 
@@ -42,10 +76,67 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case message.ErrorMsg:
+		var qe *database.QueryError
+		if errors.As(msg.Err, &qe) {
+			m.showError(qe)
+		}
+
+		return m, nil
+
+	case message.CatalogLoadedMsg:
+		if msg.Err == nil {
+			m.catalog = msg.Catalog
+		}
+
+		return m, nil
+
+	case message.LoadQueryMsg:
+		m.load(msg.Query)
+		if msg.Run {
+			return m, m.execute(msg.Query, 0)
+		}
+
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.completion != nil {
+			switch {
+			case key.Matches(msg, m.screenProps.Keymap.AcceptCompletion):
+				m.acceptCompletion()
+				return m, nil
+			case key.Matches(msg, m.screenProps.Keymap.NextCompletion):
+				m.completion.move(1)
+				return m, nil
+			case key.Matches(msg, m.screenProps.Keymap.PreviousCompletion):
+				m.completion.move(-1)
+				return m, nil
+			case key.Matches(msg, m.screenProps.Keymap.Cancel):
+				m.completion = nil
+				return m, nil
+			}
+		}
+
 		switch {
 		case key.Matches(msg, m.screenProps.Keymap.ExecuteQuery):
-			return m, m.screenProps.MessageManager.NewExecuteQueryCmd(m.textarea.Value())
+			return m, m.executeStatement()
+		case key.Matches(msg, m.screenProps.Keymap.ExecuteSelection):
+			return m, m.executeSelection()
+		case key.Matches(msg, m.screenProps.Keymap.ExecuteScript):
+			return m, m.execute(m.textarea.Value(), 0)
+		case key.Matches(msg, m.screenProps.Keymap.MarkSelection):
+			m.toggleMark()
+			return m, nil
+		case m.mark >= 0 && key.Matches(msg, m.screenProps.Keymap.Cancel):
+			m.toggleMark()
+			return m, nil
+		case key.Matches(msg, m.screenProps.Keymap.AcceptCompletion):
+			m.updateCompletion(true)
+			return m, nil
+		case key.Matches(msg, m.screenProps.Keymap.ReloadCompletions):
+			return m, m.reloadCompletions()
+		case key.Matches(msg, m.screenProps.Keymap.ShowHistory):
+			return m, m.screenProps.MessageManager.NewShowHistoryCmd()
 		case key.Matches(msg, m.screenProps.Keymap.NavigateDown):
 			cmds = append(cmds, m.screenProps.MessageManager.NewNavigateDirectionCmd(message.DirectionDown, m.id))
 		case key.Matches(msg, m.screenProps.Keymap.NavigateRight):
@@ -60,13 +151,204 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	newtextarea, cmd := m.textarea.Update(msg)
 	cmds = append(cmds, cmd)
 	m.textarea = newtextarea
+	m.reposition()
+
+	// The popup follows the word being typed, and closes once the cursor
+	// leaves it.
+	if msg, ok := msg.(tea.KeyMsg); ok && (m.completion != nil || msg.Type == tea.KeyRunes) {
+		m.updateCompletion(false)
+	}
 
 	return m, tea.Batch(cmds...)
 }
 
 // View implements tea.Model.
 func (m *Model) View() string {
-	return lipgloss.NewStyle().Width(m.width).Height(m.height).Render(m.textarea.View())
+	content := m.editorView()
+	if m.mark >= 0 {
+		start, end := m.selection()
+		hint := fmt.Sprintf("%d characters selected (%s to run, %s to clear)",
+			utf8.RuneCountInString(m.textarea.Value()[start:end]),
+			m.screenProps.Keymap.ExecuteSelection.Help().Key,
+			m.screenProps.Keymap.Cancel.Help().Key)
+		content = lipgloss.JoinVertical(lipgloss.Left, content, selectionStyle.Render(hint))
+	}
+
+	return lipgloss.NewStyle().Width(m.width).Height(m.height).Render(content)
+}
+
+// dialect is the SQL dialect of the active session, which decides how the
+// buffer is split into statements.
+func (m *Model) dialect() database.Dialect {
+	if s := m.screenProps.SessionManager.Active(); s != nil {
+		return s.Database.Dialect()
+	}
+
+	return database.DialectPostgres
+}
+
+// executeStatement runs the statement under the cursor, which is the last
+// statement that starts before it.
+func (m *Model) executeStatement() tea.Cmd {
+	value := m.textarea.Value()
+	spans := m.dialect().Split(value)
+	if len(spans) == 0 {
+		return nil
+	}
+
+	cursor := m.cursorOffset()
+	span := spans[0]
+	for _, s := range spans {
+		if s.Start <= cursor {
+			span = s
+		}
+	}
+
+	return m.execute(value[span.Start:span.End], span.Start)
+}
+
+// executeSelection runs the statements in the selection.
+func (m *Model) executeSelection() tea.Cmd {
+	if m.mark < 0 {
+		return message.NewStatusUpdateCmd("NO SELECTION", fmt.Sprintf("Press %s to start selecting", m.screenProps.Keymap.MarkSelection.Help().Key))
+	}
+
+	start, end := m.selection()
+
+	return m.execute(m.textarea.Value()[start:end], start)
+}
+
+// execute runs the statements of script one after the other. offset is
+// where script starts in the buffer. If any of them has parameters, their
+// values are asked for first.
+func (m *Model) execute(script string, offset int) tea.Cmd {
+	dialect := m.dialect()
+	spans := dialect.Split(script)
+
+	m.ran = make([]ranStatement, len(spans))
+	statements := make([]database.Statement, len(spans))
+	params := false
+	for i, span := range spans {
+		statements[i] = database.Statement{SQL: script[span.Start:span.End]}
+		m.ran[i] = ranStatement{query: statements[i].SQL, start: offset + span.Start, dialect: dialect}
+		params = params || len(dialect.Params(statements[i].SQL)) > 0
+	}
+
+	if params {
+		return m.screenProps.MessageManager.NewPromptParamsCmd(statements)
+	}
+
+	return m.screenProps.MessageManager.NewExecuteScriptCmd(statements)
+}
+
+// load replaces the buffer with query, leaving the cursor at its end.
+func (m *Model) load(query string) {
+	if m.mark >= 0 {
+		m.toggleMark()
+	}
+	m.completion = nil
+
+	m.textarea.SetValue(query)
+	m.reposition()
+}
+
+// showError moves the cursor to the position of a query error, if the
+// statement it occurred in is still where it was run from. Statements with
+// :name parameters run with them bound, so the error's position is mapped
+// back to the statement as written.
+func (m *Model) showError(qe *database.QueryError) {
+	value := m.textarea.Value()
+	for _, stmt := range m.ran {
+		if bound, _ := stmt.dialect.Bind(stmt.query, nil); bound != qe.Query || !strings.HasPrefix(value[min(stmt.start, len(value)):], stmt.query) {
+			continue
+		}
+
+		written := *qe
+		written.Query = stmt.query
+		written.Position = stmt.dialect.Unbind(stmt.query, qe.Position)
+
+		line, col, ok := written.Line()
+		if !ok {
+			return
+		}
+
+		startLine, startCol := position(value, stmt.start)
+		if line == 0 {
+			col += startCol
+		}
+
+		m.moveCursor(startLine+line, col)
+		return
+	}
+}
+
+// toggleMark starts a selection at the cursor, or clears the selection.
+func (m *Model) toggleMark() {
+	if m.mark >= 0 {
+		m.mark = -1
+		m.textarea.SetHeight(m.height)
+	} else {
+		m.mark = m.cursorOffset()
+		m.textarea.SetHeight(max(m.height-1, 1))
+	}
+
+	m.reposition()
+}
+
+// selection returns the byte offsets of the start and end of the selection.
+func (m *Model) selection() (int, int) {
+	mark := min(m.mark, len(m.textarea.Value()))
+	cursor := m.cursorOffset()
+
+	return min(mark, cursor), max(mark, cursor)
+}
+
+// cursorOffset returns the byte offset of the cursor in the buffer.
+func (m *Model) cursorOffset() int {
+	lines := strings.Split(m.textarea.Value(), "\n")
+	row := min(m.textarea.Line(), len(lines)-1)
+
+	offset := 0
+	for _, line := range lines[:row] {
+		offset += len(line) + 1
+	}
+
+	info := m.textarea.LineInfo()
+	runes := []rune(lines[row])
+	col := min(info.StartColumn+info.ColumnOffset, len(runes))
+
+	return offset + len(string(runes[:col]))
+}
+
+// position returns the 0-based line and rune column of a byte offset in s.
+func position(s string, offset int) (line, col int) {
+	before := s[:min(offset, len(s))]
+	line = strings.Count(before, "\n")
+	col = utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:])
+
+	return line, col
+}
+
+// moveCursor moves the cursor to the given 0-based line and column.
+func (m *Model) moveCursor(line, col int) {
+	m.textarea.CursorStart()
+	for m.textarea.Line() > 0 {
+		m.textarea.CursorUp()
+	}
+
+	// Soft wrapped lines take several moves to get past, so stop once the
+	// cursor no longer moves.
+	for m.textarea.Line() < line {
+		before := m.textarea.LineInfo()
+		row := m.textarea.Line()
+		m.textarea.CursorDown()
+		if m.textarea.Line() == row && m.textarea.LineInfo() == before {
+			break
+		}
+	}
+
+	m.textarea.SetCursor(col)
+	m.reposition()
 }
 
 func (m *Model) SetSize(width, height int) {
@@ -74,12 +356,19 @@ func (m *Model) SetSize(width, height int) {
 	m.height = height
 	m.textarea.SetWidth(width)
 	m.textarea.SetHeight(height)
+	if m.mark >= 0 {
+		m.textarea.SetHeight(max(height-1, 1))
+	}
+	m.reposition()
 }
 
+var selectionStyle = lipgloss.NewStyle().Faint(true)
+
 func (m *Model) Focus() {
 	m.textarea.Focus()
 }
 
 func (m *Model) Blur() {
+	m.completion = nil
 	m.textarea.Blur()
 }
@@ -0,0 +1,197 @@
+package result
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/database"
+	"github.com/davesavic/lazydb/internal/ui/common"
+)
+
+// maxHexBytes is the number of bytes of a binary value shown in the hex view.
+const maxHexBytes = 4096
+
+// detailView shows a single row as a record, with each column on its own
+// lines and values pretty-printed according to their type.
+type detailView struct {
+	viewport viewport.Model
+	columns  []database.Column
+	row      []any
+	// index is the position of the row in the result set.
+	index int
+	total int
+}
+
+func newDetailView(columns []database.Column, row []any, index, total, width, height int) *detailView {
+	d := &detailView{
+		viewport: viewport.New(width, height),
+		columns:  columns,
+		row:      row,
+		index:    index,
+		total:    total,
+	}
+	d.render()
+
+	return d
+}
+
+// setRow replaces the row shown, scrolling back to the top.
+func (d *detailView) setRow(row []any, index, total int) {
+	d.row = row
+	d.index = index
+	d.total = total
+	d.render()
+	d.viewport.GotoTop()
+}
+
+func (d *detailView) setSize(width, height int) {
+	d.viewport.Width = width
+	d.viewport.Height = height
+	d.render()
+}
+
+func (d *detailView) update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	d.viewport, cmd = d.viewport.Update(msg)
+
+	return cmd
+}
+
+func (d *detailView) render() {
+	width := max(d.viewport.Width-2, 1)
+
+	var sections []string
+	for i, col := range d.columns {
+		var v any
+		if i < len(d.row) {
+			v = d.row[i]
+		}
+
+		header := detailNameStyle.Render(columnTitle(i, col))
+		if col.TypeName != "" {
+			header += " " + detailTypeStyle.Render(col.TypeName)
+		}
+		if col.Nullable == database.NotNull {
+			header += " " + detailTypeStyle.Render("not null")
+		}
+
+		body := lipgloss.NewStyle().PaddingLeft(2).Width(width).Render(detailValue(col, v))
+		sections = append(sections, header+"\n"+body)
+	}
+
+	d.viewport.SetContent(strings.Join(sections, "\n\n"))
+}
+
+// title describes the row shown and how far it has been scrolled.
+func (d *detailView) title() string {
+	return fmt.Sprintf("Row %d of %d (%3.f%%)", d.index+1, d.total, d.viewport.ScrollPercent()*100)
+}
+
+func (d *detailView) view() string {
+	return lipgloss.JoinVertical(lipgloss.Left, summaryStyle.Render(d.title()), d.viewport.View())
+}
+
+// detailValue renders a value in full: JSON and XML are indented and
+// highlighted, and binary values are shown as a hex dump.
+func detailValue(col database.Column, v any) string {
+	f := database.FormatValue(col, v)
+
+	switch f.Kind {
+	case database.KindNull:
+		return nullStyle.Render(f.Text)
+	case database.KindBinary:
+		return hexDump(v)
+	case database.KindJSON:
+		return prettyJSON(f.Text)
+	}
+
+	text := strings.TrimSpace(f.Text)
+	switch {
+	case strings.EqualFold(col.TypeName, "xml"), strings.HasPrefix(text, "<"):
+		if pretty, ok := indentXML(text); ok {
+			return common.Highlight(pretty, "xml")
+		}
+	case strings.HasPrefix(text, "{"), strings.HasPrefix(text, "["):
+		// Text columns often hold JSON documents too.
+		if json.Valid([]byte(text)) {
+			return prettyJSON(text)
+		}
+	}
+
+	return f.Text
+}
+
+func prettyJSON(text string) string {
+	var b bytes.Buffer
+	if err := json.Indent(&b, []byte(text), "", "  "); err != nil {
+		return text
+	}
+
+	return common.Highlight(b.String(), "json")
+}
+
+// indentXML re-indents an XML document. It reports false if text is not XML.
+func indentXML(text string) (string, bool) {
+	var b strings.Builder
+
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	encoder := xml.NewEncoder(&b)
+	encoder.Indent("", "  ")
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+
+		// Whitespace between elements is replaced by the encoder's indentation.
+		if data, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		if err := encoder.EncodeToken(token); err != nil {
+			return "", false
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return "", false
+	}
+
+	return b.String(), true
+}
+
+func hexDump(v any) string {
+	data, ok := v.([]byte)
+	if !ok {
+		return fmt.Sprintf("%v", v)
+	}
+
+	if len(data) == 0 {
+		return summaryStyle.Render("(empty)")
+	}
+
+	dump := strings.TrimSuffix(hex.Dump(data[:min(len(data), maxHexBytes)]), "\n")
+	if len(data) > maxHexBytes {
+		dump += "\n" + summaryStyle.Render(fmt.Sprintf("… %s more", formatSize(len(data)-maxHexBytes)))
+	}
+
+	return dump
+}
+
+var (
+	detailNameStyle = lipgloss.NewStyle().Bold(true)
+	detailTypeStyle = lipgloss.NewStyle().Faint(true)
+)
@@ -0,0 +1,401 @@
+package result
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/database"
+	"github.com/davesavic/lazydb/internal/service/message"
+	"github.com/davesavic/lazydb/internal/ui/common"
+	"github.com/evertras/bubble-table/table"
+)
+
+// cell identifies a cell of the result set by its row and column index.
+type cell struct {
+	row    int
+	column int
+}
+
+// editable reports whether the column at index i can be edited, which needs
+// it to be read from the table the result set's rows can be changed in.
+func (m *tab) editable(i int) bool {
+	source := m.results.Source
+	if source == nil || i >= len(m.results.Columns) {
+		return false
+	}
+
+	col := m.results.Columns[i]
+	return col.Schema == source.Schema && col.Table == source.Table && col.TableColumn != ""
+}
+
+// readOnly reports whether the results were read through a read-only
+// connection, which cannot change them.
+func (m *tab) readOnly() bool {
+	s, ok := m.screenProps.SessionManager.Get(m.session)
+	return ok && s.Config.ReadOnly
+}
+
+// readOnlyCmd tells the user that the results cannot be changed.
+func (m *tab) readOnlyCmd() tea.Cmd {
+	return message.NewStatusUpdateCmd("READ ONLY", fmt.Sprintf("%s is a read-only connection", m.session))
+}
+
+// startEdit opens the editor on the cell under the cursor.
+func (m *tab) startEdit() tea.Cmd {
+	if m.results == nil || m.rowCount == 0 {
+		return nil
+	}
+
+	if m.readOnly() {
+		return m.readOnlyCmd()
+	}
+
+	if m.results.Source == nil {
+		return message.NewStatusUpdateCmd("READ ONLY", "Only results read from a single table with a primary key can be edited")
+	}
+
+	if !m.editable(m.column) {
+		return message.NewStatusUpdateCmd("READ ONLY", fmt.Sprintf("%s is not a column of %s", columnTitle(m.column, m.results.Columns[m.column]), m.results.Source.Table))
+	}
+
+	c := cell{row: m.table.GetHighlightedRowIndex(), column: m.column}
+	if c.row >= m.rowCount {
+		return nil
+	}
+
+	value, staged := m.edits[c]
+	if !staged {
+		value = m.rows[c.row][c.column]
+	}
+
+	input := textinput.New()
+	input.Prompt = columnTitle(c.column, m.results.Columns[c.column]) + " = "
+	input.Placeholder = "NULL"
+	if value != nil {
+		input.SetValue(database.FormatValue(m.results.Columns[c.column], value).Text)
+	}
+	input.Width = max(m.width-lipgloss.Width(input.Prompt)-1, 1)
+
+	m.editor = &input
+	m.editing = c
+
+	return m.editor.Focus()
+}
+
+// updateEditor handles keys while a cell is being edited. Values are staged
+// as text, which the database converts to the column's type.
+func (m *tab) updateEditor(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.screenProps.Keymap.Cancel):
+		m.editor = nil
+		return nil
+	case key.Matches(msg, m.screenProps.Keymap.SetNull):
+		m.stage(m.editing, nil)
+		return nil
+	case msg.Type == tea.KeyEnter:
+		m.stage(m.editing, m.editor.Value())
+		return nil
+	}
+
+	var cmd tea.Cmd
+	*m.editor, cmd = m.editor.Update(msg)
+
+	return cmd
+}
+
+// stage records value as the new value of c and closes the editor. A value
+// equal to the cell's current one discards the cell's staged change.
+func (m *tab) stage(c cell, value any) {
+	m.editor = nil
+
+	original := m.rows[c.row][c.column]
+	unchanged := value == nil && original == nil
+	if text, ok := value.(string); ok && original != nil {
+		unchanged = text == database.FormatValue(m.results.Columns[c.column], original).Text
+	}
+
+	if unchanged {
+		delete(m.edits, c)
+	} else {
+		m.edits[c] = value
+	}
+
+	m.fitColumn(c.column, cellText(database.FormatValue(m.results.Columns[c.column], value)))
+	m.updateRows()
+}
+
+// fitColumn widens the column at index i if text would not fit in it.
+func (m *tab) fitColumn(i int, text string) {
+	if w := lipgloss.Width(text) + 2; w > m.columnWidths[i] {
+		m.columnWidths[i] = w
+		newTable := m.table.WithColumns(m.tableColumns())
+		m.table = &newTable
+	}
+}
+
+// revert discards the staged change of the cell under the cursor.
+func (m *tab) revert() {
+	c := cell{row: m.table.GetHighlightedRowIndex(), column: m.column}
+	if _, ok := m.edits[c]; !ok {
+		return
+	}
+
+	delete(m.edits, c)
+	m.updateRows()
+}
+
+// startInsert opens the form for the values of a new row.
+func (m *tab) startInsert() tea.Cmd {
+	if m.readOnly() {
+		return m.readOnlyCmd()
+	}
+
+	if m.results == nil || m.results.Source == nil {
+		return message.NewStatusUpdateCmd("READ ONLY", "Rows can only be inserted into results read from a single table with a primary key")
+	}
+
+	m.inserter = newInsertForm(m.results.Source, m.width, m.height)
+
+	return m.inserter.form.Init()
+}
+
+// updateInserter handles messages while the form for a new row is open.
+func (m *tab) updateInserter(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok && key.Matches(msg, m.screenProps.Keymap.Cancel) {
+		m.inserter = nil
+		return nil
+	}
+
+	newForm, cmd := m.inserter.form.Update(msg)
+	if f, ok := newForm.(*huh.Form); ok {
+		m.inserter.form = f
+	}
+
+	if m.inserter.form.State == huh.StateCompleted {
+		set := m.inserter.assignments()
+		m.inserts = append(m.inserts, set)
+		m.inserter = nil
+
+		for i, col := range m.results.Columns {
+			if m.editable(i) {
+				m.fitColumn(i, insertedText(col, set))
+			}
+		}
+		m.updateRows()
+
+		// Show the new row, which is added after the fetched rows.
+		newTable := m.table.WithHighlightedRow(m.rowCount + len(m.inserts) - 1)
+		m.table = &newTable
+
+		return nil
+	}
+
+	return cmd
+}
+
+// toggleDelete marks the highlighted row for deletion, or unmarks it. A new
+// row that has not been inserted yet is discarded instead.
+func (m *tab) toggleDelete() tea.Cmd {
+	if m.readOnly() {
+		return m.readOnlyCmd()
+	}
+
+	if m.results == nil || m.results.Source == nil {
+		return message.NewStatusUpdateCmd("READ ONLY", "Rows can only be deleted from results read from a single table with a primary key")
+	}
+
+	row := m.table.GetHighlightedRowIndex()
+	switch {
+	case row >= m.rowCount+len(m.inserts):
+		return nil
+	case row >= m.rowCount:
+		m.inserts = slices.Delete(m.inserts, row-m.rowCount, row-m.rowCount+1)
+	case m.deletes[row]:
+		delete(m.deletes, row)
+	default:
+		m.deletes[row] = true
+	}
+
+	m.updateRows()
+
+	return nil
+}
+
+// staged returns the number of staged changes.
+func (m *tab) staged() int {
+	return len(m.edits) + len(m.inserts) + len(m.deletes)
+}
+
+// clearStaged discards all staged changes.
+func (m *tab) clearStaged() {
+	clear(m.edits)
+	clear(m.deletes)
+	m.inserts = nil
+}
+
+// insertedRow renders a new row that has not been inserted yet. Columns that
+// are not given a value show that they get their default.
+func (m *tab) insertedRow(set []database.Assignment) table.RowData {
+	data := make(table.RowData, len(m.results.Columns))
+	for i, col := range m.results.Columns {
+		if !m.editable(i) {
+			continue
+		}
+
+		style := stagedStyle
+		if !slices.ContainsFunc(set, func(a database.Assignment) bool { return a.Column == col.TableColumn }) {
+			style = style.Faint(true)
+		}
+
+		data[columnKey(i)] = table.NewStyledCell(insertedText(col, set), style)
+	}
+
+	return data
+}
+
+// insertedText is the text shown for col in a new row with the assigned
+// values.
+func insertedText(col database.Column, set []database.Assignment) string {
+	for _, a := range set {
+		if a.Column == col.TableColumn {
+			return cellText(database.FormatValue(col, a.Value))
+		}
+	}
+
+	return "DEFAULT"
+}
+
+// rowKey returns the primary key values the row was read with.
+func (m *tab) rowKey(row int) []any {
+	source := m.results.Source
+
+	var key []any
+	for _, name := range source.PrimaryKey() {
+		key = append(key, m.rows[row][database.ColumnIndex(m.results.Columns, source, name)])
+	}
+
+	return key
+}
+
+// statements returns the statements that apply the staged changes: a DELETE
+// for each row marked for deletion, an UPDATE for each other row with edited
+// cells, and an INSERT for each new row. Rows are matched by the primary key
+// values they were read with, so a change to the key itself is applied to the
+// right row.
+func (m *tab) statements(dialect database.Dialect) []database.Statement {
+	source := m.results.Source
+
+	var statements []database.Statement
+	for _, row := range slices.Sorted(maps.Keys(m.deletes)) {
+		statements = append(statements, dialect.Delete(source, m.rowKey(row)))
+	}
+
+	changed := make(map[int][]int)
+	for c := range m.edits {
+		if !m.deletes[c.row] {
+			changed[c.row] = append(changed[c.row], c.column)
+		}
+	}
+
+	for _, row := range slices.Sorted(maps.Keys(changed)) {
+		columns := changed[row]
+		slices.Sort(columns)
+
+		set := make([]database.Assignment, len(columns))
+		for i, column := range columns {
+			set[i] = database.Assignment{
+				Column: m.results.Columns[column].TableColumn,
+				Value:  m.edits[cell{row: row, column: column}],
+			}
+		}
+
+		statements = append(statements, dialect.Update(source, set, m.rowKey(row)))
+	}
+
+	for _, set := range m.inserts {
+		statements = append(statements, dialect.Insert(source, set))
+	}
+
+	return statements
+}
+
+// openReview shows the statements that apply the staged changes.
+func (m *tab) openReview() tea.Cmd {
+	if m.staged() == 0 {
+		return message.NewStatusUpdateCmd("NO CHANGES", "There are no staged changes to apply")
+	}
+
+	s, ok := m.screenProps.SessionManager.Get(m.session)
+	if !ok {
+		return nil
+	}
+
+	m.review = newReviewView(m.statements(s.Database.Dialect()), m.width, m.height-1)
+
+	return nil
+}
+
+// updateReview handles keys while the staged changes are reviewed.
+func (m *tab) updateReview(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.screenProps.Keymap.Cancel):
+		m.review = nil
+		return nil
+	case key.Matches(msg, m.screenProps.Keymap.Confirm):
+		statements := m.review.statements
+		m.review = nil
+
+		// Applying the changes closes the result set, so no more of its rows
+		// can be fetched.
+		m.hasMore = false
+		m.running = true
+		m.activity = "Applying changes…"
+		m.startedAt = time.Now()
+
+		return tea.Batch(
+			m.spinner.Tick,
+			m.screenProps.MessageManager.NewApplyChangesCmd(m.session, statements),
+		)
+	}
+
+	var cmd tea.Cmd
+	m.review.viewport, cmd = m.review.viewport.Update(msg)
+
+	return cmd
+}
+
+// reviewView shows the statements that apply staged changes before they are
+// executed.
+type reviewView struct {
+	viewport   viewport.Model
+	statements []database.Statement
+}
+
+func newReviewView(statements []database.Statement, width, height int) *reviewView {
+	previews := make([]string, len(statements))
+	for i, stmt := range statements {
+		previews[i] = stmt.Preview
+	}
+
+	r := &reviewView{
+		viewport:   viewport.New(width, height),
+		statements: statements,
+	}
+	r.viewport.SetContent(lipgloss.NewStyle().Width(width).Render(common.Highlight(strings.Join(previews, "\n"), "sql")))
+
+	return r
+}
+
+func (r *reviewView) view(keymap string) string {
+	title := fmt.Sprintf("%d statements will be executed in a single transaction (%s)", len(r.statements), keymap)
+	return lipgloss.JoinVertical(lipgloss.Left, summaryStyle.Render(title), r.viewport.View())
+}
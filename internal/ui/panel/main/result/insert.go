@@ -0,0 +1,90 @@
+package result
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/davesavic/lazydb/internal/service/database"
+)
+
+// insertForm prompts for the values of a new row of a table. Columns left
+// empty are not inserted, so they get their default value.
+type insertForm struct {
+	form    *huh.Form
+	columns []database.TableColumn
+	values  []string
+}
+
+func newInsertForm(source *database.TableSource, width, height int) *insertForm {
+	f := &insertForm{}
+
+	for _, col := range source.Columns {
+		if !col.Generated {
+			f.columns = append(f.columns, col)
+		}
+	}
+
+	f.values = make([]string, len(f.columns))
+
+	fields := make([]huh.Field, len(f.columns))
+	for i, col := range f.columns {
+		input := huh.NewInput().
+			Title(col.Name).
+			Description(describeTableColumn(col)).
+			Value(&f.values[i])
+
+		if col.Default != "" {
+			input = input.Placeholder(col.Default)
+		}
+
+		// Columns that cannot be NULL and have no default must be given a
+		// value, or the insert would fail.
+		if !col.Nullable && col.Default == "" {
+			input = input.Validate(func(s string) error {
+				if s == "" {
+					return errors.New("a value is required")
+				}
+				return nil
+			})
+		}
+
+		fields[i] = input
+	}
+
+	f.form = huh.NewForm(huh.NewGroup(fields...)).
+		WithWidth(width).
+		WithHeight(height).
+		WithShowHelp(false)
+
+	return f
+}
+
+// assignments returns the values given for the new row.
+func (f *insertForm) assignments() []database.Assignment {
+	var set []database.Assignment
+	for i, col := range f.columns {
+		if f.values[i] != "" {
+			set = append(set, database.Assignment{Column: col.Name, Value: f.values[i]})
+		}
+	}
+
+	return set
+}
+
+// describeTableColumn summarises a column's type and constraints, e.g.
+// "integer · NOT NULL · primary key".
+func describeTableColumn(col database.TableColumn) string {
+	parts := []string{col.TypeName}
+	if !col.Nullable {
+		parts = append(parts, "NOT NULL")
+	}
+	if col.PrimaryKey {
+		parts = append(parts, "primary key")
+	}
+	if col.Default != "" {
+		parts = append(parts, "default "+col.Default)
+	}
+
+	return strings.Join(parts, " · ")
+}
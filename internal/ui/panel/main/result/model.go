@@ -1,34 +1,38 @@
 package result
 
 import (
-	"fmt"
+	"strconv"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/davesavic/lazydb/internal/service/database"
 	"github.com/davesavic/lazydb/internal/service/message"
 	"github.com/davesavic/lazydb/internal/ui/common"
-	"github.com/evertras/bubble-table/table"
 )
 
 var _ tea.Model = &Model{}
 
+// Model is the results panel. Each statement of a run gets a tab that shows
+// its results, which replace the tabs of the previous run unless they are
+// pinned.
 type Model struct {
-	id          string
 	screenProps *common.ScreenProps
 	width       int
 	height      int
+	focused     bool
 
-	table   *table.Model
-	results *database.QueryResult
+	tabs []*tab
+	// active is the index of the tab that is shown, and current the index of
+	// the tab that shows the results of the running or latest query.
+	active  int
+	current int
 }
 
 func NewModel(props *common.ScreenProps) *Model {
 	return &Model{
-		id:          "results",
 		screenProps: props,
-		table:       nil,
+		tabs:        []*tab{newTab(props, "")},
 	}
 }
 
@@ -39,115 +43,176 @@ func (m *Model) Init() tea.Cmd {
 
 // Update implements tea.Model.
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-
 	switch msg := msg.(type) {
-	case message.QueryExecutedMsg:
-		m.results = msg.Result
-
-		columns := make([]table.Column, 0)
-		if len(m.results.Columns) > 0 {
-			colWidths := calculateColumnWidths(m.results.Columns, m.results.Rows)
-
-			for _, title := range m.results.Columns {
-				columns = append(columns, table.NewColumn(title, title, colWidths[title]))
+	case message.QueryStartedMsg:
+		switch msg.Target {
+		case message.ResultsAppend:
+			m.tabs = append(m.tabs, newTab(m.screenProps, msg.Session))
+			m.current = len(m.tabs) - 1
+		case message.ResultsRefresh:
+		default:
+			var pinned []*tab
+			for _, t := range m.tabs {
+				if t.pinned {
+					pinned = append(pinned, t)
+				}
 			}
+			m.tabs = append(pinned, newTab(m.screenProps, msg.Session))
+			m.current = len(m.tabs) - 1
 		}
 
-		rows := make([]table.Row, 0)
-		for _, row := range m.results.Rows {
-			rows = append(rows, table.NewRow(row))
+		// Running a query closes the result set of the previous one, so no
+		// more of its rows can be fetched.
+		for i, t := range m.tabs {
+			if i != m.current {
+				t.hasMore = false
+			}
 		}
 
-		t := table.
-			New(columns).
-			WithRows(rows).
-			HeaderStyle(lipgloss.NewStyle().Bold(true)).
-			WithPageSize(15).
-			WithMaxTotalWidth(m.width).WithPaginationWrapping(false)
+		m.active = m.current
+		m.layoutTabs()
+
+		return m, m.tabs[m.current].update(msg)
+
+	case message.QueryExecutedMsg, message.QueryCancelledMsg, message.RowsFetchedMsg,
+		message.ChangesAppliedMsg, message.ErrorMsg:
+		return m, m.tabs[m.current].update(msg)
 
-		m.table = &t
+	case spinner.TickMsg:
+		var cmds []tea.Cmd
+		for _, t := range m.tabs {
+			cmds = append(cmds, t.update(msg))
+		}
+		return m, tea.Batch(cmds...)
 
 	case tea.KeyMsg:
-		switch {
-		case key.Matches(msg, m.screenProps.Keymap.NavigateDown):
-			cmds = append(cmds, m.screenProps.MessageManager.NewNavigateDirectionCmd("down", m.id))
-		case key.Matches(msg, m.screenProps.Keymap.NavigateRight):
-			cmds = append(cmds, m.screenProps.MessageManager.NewNavigateDirectionCmd("right", m.id))
-		case key.Matches(msg, m.screenProps.Keymap.NavigateUp):
-			cmds = append(cmds, m.screenProps.MessageManager.NewNavigateDirectionCmd("up", m.id))
-		case key.Matches(msg, m.screenProps.Keymap.NavigateLeft):
-			cmds = append(cmds, m.screenProps.MessageManager.NewNavigateDirectionCmd("left", m.id))
+		t := m.tabs[m.active]
+		if !t.capturesKeys() {
+			switch {
+			case key.Matches(msg, m.screenProps.Keymap.NextTab):
+				m.switchTab(1)
+				return m, nil
+			case key.Matches(msg, m.screenProps.Keymap.PreviousTab):
+				m.switchTab(-1)
+				return m, nil
+			case key.Matches(msg, m.screenProps.Keymap.PinTab):
+				return m, m.togglePin(t)
+			}
+		}
+
+		cmd := t.update(msg)
+
+		// Changes applied from this tab are shown by refreshing it.
+		if t.running {
+			m.current = m.active
 		}
-	}
 
-	if m.table != nil {
-		newTable, cmd := m.table.Update(msg)
-		m.table = &newTable
-		cmds = append(cmds, cmd)
+		return m, cmd
 	}
 
-	return m, tea.Batch(cmds...)
+	return m, m.tabs[m.active].update(msg)
 }
 
-// View implements tea.Model.
-func (m *Model) View() string {
-	if m.table == nil {
-		return lipgloss.NewStyle().
-			Width(m.width).
-			Height(m.height).
-			Render("No results")
+// switchTab shows the tab delta tabs away from the active one, wrapping
+// around.
+func (m *Model) switchTab(delta int) {
+	m.tabs[m.active].blur()
+	m.active = (m.active + delta + len(m.tabs)) % len(m.tabs)
+	if m.focused {
+		m.tabs[m.active].focus()
 	}
-
-	return lipgloss.NewStyle().
-		Width(m.width).
-		Height(m.height).
-		Render(m.table.View())
 }
 
-func (m *Model) SetSize(width, height int) {
-	m.width = width
-	m.height = height
+// togglePin pins or unpins t.
+func (m *Model) togglePin(t *tab) tea.Cmd {
+	t.pinned = !t.pinned
+	m.layoutTabs()
 
-	if m.table != nil {
-		newTable := m.table.WithTargetWidth(width)
-		m.table = &newTable
+	if t.pinned {
+		return message.NewStatusUpdateCmd("PINNED", "The tab is kept when the next query runs")
 	}
+
+	return message.NewStatusUpdateCmd("UNPINNED", "The tab is replaced when the next query runs")
 }
 
-func (m *Model) Focus() {
-	if m.table != nil {
-		newTable := m.table.Focused(true)
-		m.table = &newTable
-	}
+// showTabBar reports whether the tab bar is shown, which it is when there is
+// more than one tab or a tab is pinned.
+func (m *Model) showTabBar() bool {
+	return len(m.tabs) > 1 || m.tabs[0].pinned
 }
 
-func (m *Model) Blur() {
-	if m.table != nil {
-		newTable := m.table.Focused(false)
-		m.table = &newTable
+// layoutTabs sizes the tabs to fit below the tab bar and focuses the active
+// tab.
+func (m *Model) layoutTabs() {
+	height := m.height
+	if m.showTabBar() {
+		height--
 	}
-}
 
-func calculateColumnWidths(columns []string, rows []map[string]any) map[string]int {
-	widths := make(map[string]int)
+	for i, t := range m.tabs {
+		t.setSize(m.width, height)
+
+		if m.focused && i == m.active {
+			t.focus()
+		} else {
+			t.blur()
+		}
+	}
+}
 
-	for _, col := range columns {
-		widths[col] = len(col)
+// View implements tea.Model.
+func (m *Model) View() string {
+	if !m.showTabBar() {
+		return m.tabs[0].view()
 	}
 
-	for _, row := range rows {
-		for col, val := range row {
-			strVal := fmt.Sprintf("%v", val)
-			if len(strVal) > widths[col] {
-				widths[col] = len(strVal)
-			}
+	return lipgloss.JoinVertical(lipgloss.Left, m.tabBar(), m.tabs[m.active].view())
+}
+
+// tabBar lists the tabs by number and title, marking pinned tabs with an
+// asterisk. Tabs are dropped from the start of the bar until the active tab
+// fits.
+func (m *Model) tabBar() string {
+	titles := make([]string, len(m.tabs))
+	for i, t := range m.tabs {
+		style := tabStyle
+		if i == m.active {
+			style = activeTabStyle
+		}
+
+		number := strconv.Itoa(i + 1)
+		if t.pinned {
+			number += "*"
 		}
+
+		titles[i] = style.Render(number + " " + t.title())
 	}
 
-	for col := range widths {
-		widths[col] += 2
+	first := 0
+	for first < m.active && lipgloss.Width(lipgloss.JoinHorizontal(lipgloss.Top, titles[first:m.active+1]...)) > m.width {
+		first++
 	}
 
-	return widths
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(lipgloss.JoinHorizontal(lipgloss.Top, titles[first:]...))
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.layoutTabs()
+}
+
+func (m *Model) Focus() {
+	m.focused = true
+	m.tabs[m.active].focus()
+}
+
+func (m *Model) Blur() {
+	m.focused = false
+	m.tabs[m.active].blur()
 }
+
+var (
+	tabStyle       = lipgloss.NewStyle().Padding(0, 1).Faint(true)
+	activeTabStyle = lipgloss.NewStyle().Padding(0, 1).Bold(true).Reverse(true)
+)
@@ -0,0 +1,725 @@
+package result
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/database"
+	"github.com/davesavic/lazydb/internal/service/message"
+	"github.com/davesavic/lazydb/internal/ui/common"
+	"github.com/evertras/bubble-table/table"
+)
+
+// tab shows the results of a single statement.
+type tab struct {
+	id          string
+	screenProps *common.ScreenProps
+	width       int
+	height      int
+
+	focused bool
+	table   *table.Model
+	session string
+	// pinned is set to keep the tab when the next query replaces the results.
+	pinned bool
+	// query is the query that produced the results, with the arguments it
+	// was run with, which is run again to show them once changes to them
+	// have been applied.
+	query    string
+	args     []any
+	results  *database.QueryResult
+	duration time.Duration
+	err      error
+
+	// Rows and counts of the result set, copied when a batch of rows arrives
+	// as the result set is written to while more rows are fetched.
+	rows     [][]any
+	rowCount int
+	hasMore  bool
+	capped   bool
+	fetching bool
+
+	// detail shows the highlighted row as a record while it is open.
+	detail *detailView
+
+	// column is the index of the column under the cursor, and columnWidths
+	// the widths of all columns.
+	column       int
+	columnWidths []int
+
+	// edits holds the staged new values of cells, and editor the input for
+	// the cell being edited while it is open.
+	edits   map[cell]any
+	editor  *textinput.Model
+	editing cell
+	review  *reviewView
+
+	// inserts holds the values of new rows and deletes the indexes of rows
+	// marked for deletion, and inserter the form for a new row while it is
+	// open.
+	inserts  [][]database.Assignment
+	deletes  map[int]bool
+	inserter *insertForm
+
+	// State of the in-flight query
+	running   bool
+	activity  string
+	startedAt time.Time
+	spinner   spinner.Model
+}
+
+func newTab(props *common.ScreenProps, session string) *tab {
+	return &tab{
+		id:          "results",
+		screenProps: props,
+		session:     session,
+		edits:       make(map[cell]any),
+		deletes:     make(map[int]bool),
+		spinner:     spinner.New(spinner.WithSpinner(spinner.Dot)),
+	}
+}
+
+func (m *tab) update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case message.QueryStartedMsg:
+		m.err = nil
+		m.detail = nil
+		m.editor = nil
+		m.review = nil
+		m.inserter = nil
+		m.query = msg.Query
+		m.args = msg.Args
+		m.running = true
+		m.activity = "Running query…"
+		m.startedAt = msg.StartedAt
+		cmds = append(cmds, m.spinner.Tick)
+
+	case message.QueryCancelledMsg:
+		m.running = false
+		m.fetching = false
+		m.hasMore = false
+
+	case spinner.TickMsg:
+		if !m.running {
+			return nil
+		}
+
+		newSpinner, cmd := m.spinner.Update(msg)
+		m.spinner = newSpinner
+		return cmd
+
+	case message.ChangesAppliedMsg:
+		m.running = false
+		m.clearStaged()
+		cmds = append(cmds, m.screenProps.MessageManager.NewRefreshQueryCmd(m.session, m.query, m.args))
+
+	case message.ErrorMsg:
+		m.running = false
+		m.fetching = false
+		m.err = msg.Err
+
+	case message.RowsFetchedMsg:
+		m.fetching = false
+		m.updateCounts()
+
+		if m.table != nil {
+			newTable := m.table.WithRows(m.tableRows())
+			m.table = &newTable
+		}
+
+		if m.detail != nil {
+			m.detail.total = m.rowCount
+		}
+
+	case message.QueryExecutedMsg:
+		m.running = false
+		m.err = nil
+		m.session = msg.Session
+		m.results = msg.Result
+		m.duration = msg.Duration
+		m.detail = nil
+		m.column = 0
+		m.clearStaged()
+		m.updateCounts()
+
+		// Statements that return no rows only report the rows they changed.
+		if len(m.results.Columns) == 0 {
+			m.table = nil
+			break
+		}
+
+		m.columnWidths = calculateColumnWidths(m.results.Columns, m.rows)
+
+		t := table.
+			New(m.tableColumns()).
+			WithRows(m.tableRows()).
+			HeaderStyle(lipgloss.NewStyle().Bold(true)).
+			WithBaseStyle(lipgloss.NewStyle().Align(lipgloss.Left)).
+			WithPageSize(15).
+			WithMaxTotalWidth(m.width).WithPaginationWrapping(false).
+			Focused(m.focused)
+
+		m.table = &t
+
+	case tea.KeyMsg:
+		switch {
+		case m.inserter != nil:
+			return m.updateInserter(msg)
+		case m.editor != nil:
+			return m.updateEditor(msg)
+		case m.review != nil:
+			return m.updateReview(msg)
+		case m.detail != nil:
+			return m.updateDetail(msg)
+		case m.running:
+			return m.navigate(msg)
+		}
+
+		if m.err != nil && m.table != nil && key.Matches(msg, m.screenProps.Keymap.Cancel) {
+			m.err = nil
+			return nil
+		}
+
+		if m.table != nil && m.err == nil {
+			switch {
+			case key.Matches(msg, m.screenProps.Keymap.ViewRow):
+				m.openDetail()
+				return nil
+			case key.Matches(msg, m.screenProps.Keymap.NextColumn):
+				m.moveColumn(1)
+				return nil
+			case key.Matches(msg, m.screenProps.Keymap.PreviousColumn):
+				m.moveColumn(-1)
+				return nil
+			case key.Matches(msg, m.screenProps.Keymap.EditCell):
+				return m.startEdit()
+			case key.Matches(msg, m.screenProps.Keymap.InsertRow):
+				return m.startInsert()
+			case key.Matches(msg, m.screenProps.Keymap.DeleteRow):
+				return m.toggleDelete()
+			case key.Matches(msg, m.screenProps.Keymap.RevertCell):
+				m.revert()
+				return nil
+			case key.Matches(msg, m.screenProps.Keymap.ReviewChanges):
+				return m.openReview()
+			}
+		}
+
+		cmds = append(cmds, m.navigate(msg))
+
+	default:
+		// Forms and inputs need their own messages, e.g. to move between
+		// fields or blink the cursor.
+		switch {
+		case m.inserter != nil:
+			return m.updateInserter(msg)
+		case m.editor != nil:
+			var cmd tea.Cmd
+			*m.editor, cmd = m.editor.Update(msg)
+			return cmd
+		}
+	}
+
+	if m.table != nil {
+		newTable, cmd := m.table.Update(msg)
+		m.table = &newTable
+		cmds = append(cmds, cmd)
+
+		// Fetch the next batch once the last page of fetched rows is reached.
+		if _, ok := msg.(tea.KeyMsg); ok && m.hasMore && !m.fetching && m.table.CurrentPage() >= m.table.MaxPages() {
+			m.fetching = true
+			cmds = append(cmds, m.screenProps.MessageManager.NewFetchRowsCmd(m.session))
+		}
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// title names the tab after the statement it shows the results of, e.g.
+// "SELECT (20 rows)".
+func (m *tab) title() string {
+	var command string
+	if fields := strings.Fields(m.query); len(fields) > 0 {
+		command = strings.ToUpper(fields[0])
+	}
+
+	switch {
+	case m.running:
+		return command + " …"
+	case m.err != nil:
+		return command + " (error)"
+	case m.results == nil:
+		return command
+	case m.results.RowsAffected >= 0:
+		return fmt.Sprintf("%s (%d affected)", m.results.Command, m.results.RowsAffected)
+	case m.hasMore:
+		return fmt.Sprintf("%s (%d+ rows)", command, m.rowCount)
+	}
+
+	return fmt.Sprintf("%s (%d rows)", command, m.rowCount)
+}
+
+// capturesKeys reports whether a view that handles every key itself, such
+// as the cell editor, is open.
+func (m *tab) capturesKeys() bool {
+	return m.inserter != nil || m.editor != nil || m.review != nil || m.detail != nil
+}
+
+// navigate moves focus to a neighbouring panel if msg is a navigation key.
+func (m *tab) navigate(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.screenProps.Keymap.NavigateDown):
+		return m.screenProps.MessageManager.NewNavigateDirectionCmd("down", m.id)
+	case key.Matches(msg, m.screenProps.Keymap.NavigateRight):
+		return m.screenProps.MessageManager.NewNavigateDirectionCmd("right", m.id)
+	case key.Matches(msg, m.screenProps.Keymap.NavigateUp):
+		return m.screenProps.MessageManager.NewNavigateDirectionCmd("up", m.id)
+	case key.Matches(msg, m.screenProps.Keymap.NavigateLeft):
+		return m.screenProps.MessageManager.NewNavigateDirectionCmd("left", m.id)
+	}
+
+	return nil
+}
+
+// openDetail shows the highlighted row as a record.
+func (m *tab) openDetail() {
+	if m.table == nil || m.rowCount == 0 {
+		return
+	}
+
+	index := m.table.GetHighlightedRowIndex()
+	if index >= m.rowCount {
+		return
+	}
+
+	m.detail = newDetailView(m.results.Columns, m.rows[index], index, m.rowCount, m.width, m.height-1)
+}
+
+// updateDetail handles keys while the record view is open. The view is
+// scrolled with the usual keys and moves between rows, keeping the table's
+// highlighted row in step so the view returns to the row last shown.
+func (m *tab) updateDetail(msg tea.KeyMsg) tea.Cmd {
+	index := m.detail.index
+
+	switch {
+	case key.Matches(msg, m.screenProps.Keymap.Cancel), key.Matches(msg, m.screenProps.Keymap.ViewRow):
+		m.detail = nil
+		return nil
+	case key.Matches(msg, m.screenProps.Keymap.PreviousRow):
+		index--
+	case key.Matches(msg, m.screenProps.Keymap.NextRow):
+		index++
+	default:
+		if cmd := m.navigate(msg); cmd != nil {
+			return cmd
+		}
+
+		return m.detail.update(msg)
+	}
+
+	if index < 0 || index >= m.rowCount {
+		return nil
+	}
+
+	m.detail.setRow(m.rows[index], index, m.rowCount)
+
+	newTable := m.table.WithHighlightedRow(index)
+	m.table = &newTable
+
+	// Fetch the next batch when stepping onto the last fetched row.
+	if index == m.rowCount-1 && m.hasMore && !m.fetching {
+		m.fetching = true
+		return m.screenProps.MessageManager.NewFetchRowsCmd(m.session)
+	}
+
+	return nil
+}
+
+// updateCounts copies the row counts of the result set. It must only be
+// called while no rows are being fetched.
+func (m *tab) updateCounts() {
+	m.rows = m.results.Rows[:len(m.results.Rows):len(m.results.Rows)]
+	m.rowCount = len(m.rows)
+	m.hasMore = m.results.HasMore
+	m.capped = m.results.Capped
+}
+
+func (m *tab) tableRows() []table.Row {
+	rows := make([]table.Row, 0, len(m.rows)+len(m.inserts))
+	for r, values := range m.rows {
+		data := make(table.RowData, len(values))
+		for i, v := range values {
+			if m.deletes[r] {
+				data[columnKey(i)] = table.NewStyledCell(cellText(database.FormatValue(m.results.Columns[i], v)), deletedStyle)
+				continue
+			}
+
+			if staged, ok := m.edits[cell{row: r, column: i}]; ok {
+				data[columnKey(i)] = table.NewStyledCell(cellText(database.FormatValue(m.results.Columns[i], staged)), stagedStyle)
+				continue
+			}
+
+			data[columnKey(i)] = m.cell(i, v)
+		}
+
+		rows = append(rows, table.NewRow(data))
+	}
+
+	for _, set := range m.inserts {
+		rows = append(rows, table.NewRow(m.insertedRow(set)))
+	}
+
+	return rows
+}
+
+// updateRows shows the rows fetched so far with their staged changes.
+func (m *tab) updateRows() {
+	if m.table == nil {
+		return
+	}
+
+	newTable := m.table.WithRows(m.tableRows())
+	m.table = &newTable
+}
+
+// tableColumns returns the table's columns, with the column under the cursor
+// highlighted while the table is focused.
+func (m *tab) tableColumns() []table.Column {
+	// Columns are keyed by position as names may repeat, e.g. when selecting
+	// a.id and b.id.
+	columns := make([]table.Column, 0, len(m.results.Columns))
+	for i, col := range m.results.Columns {
+		column := table.NewColumn(columnKey(i), columnTitle(i, col), m.columnWidths[i])
+		if m.focused && i == m.column {
+			column = column.WithStyle(cursorColumnStyle)
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+// moveColumn moves the cursor by delta columns, scrolling the table so the
+// column under the cursor stays in view.
+func (m *tab) moveColumn(delta int) {
+	m.column = min(max(m.column+delta, 0), max(len(m.results.Columns)-1, 0))
+
+	newTable := m.table.WithColumns(m.tableColumns())
+	m.table = &newTable
+
+	for m.table.GetHorizontalScrollColumnOffset() > m.column {
+		newTable, _ := m.table.Update(tea.KeyMsg{Type: tea.KeyShiftLeft})
+		m.table = &newTable
+	}
+
+	for !m.columnVisible() {
+		offset := m.table.GetHorizontalScrollColumnOffset()
+		newTable, _ := m.table.Update(tea.KeyMsg{Type: tea.KeyShiftRight})
+		m.table = &newTable
+
+		if m.table.GetHorizontalScrollColumnOffset() == offset {
+			break
+		}
+	}
+}
+
+// columnVisible reports whether the column under the cursor fits in the
+// table's width at its current horizontal scroll position.
+func (m *tab) columnVisible() bool {
+	// The outer border and the overflow indicators take up three columns.
+	width := 3
+	for i := m.table.GetHorizontalScrollColumnOffset(); i <= m.column; i++ {
+		width += m.columnWidths[i] + 1
+	}
+
+	return width <= m.width
+}
+
+// cell renders the value v of the column at index i for display in the table.
+func (m *tab) cell(i int, v any) table.StyledCell {
+	var col database.Column
+	if i < len(m.results.Columns) {
+		col = m.results.Columns[i]
+	}
+
+	f := database.FormatValue(col, v)
+
+	switch f.Kind {
+	case database.KindNull:
+		return table.NewStyledCell(f.Text, nullStyle)
+	case database.KindNumber:
+		return table.NewStyledCell(cellText(f), numberStyle)
+	default:
+		return table.NewStyledCell(cellText(f), lipgloss.NewStyle())
+	}
+}
+
+// summary describes how many rows have been fetched, or how many rows a
+// statement that returns none changed.
+func (m *tab) summary() string {
+	var summary string
+	switch {
+	case m.results.RowsAffected >= 0:
+		summary = fmt.Sprintf("%s: %d rows affected", m.results.Command, m.results.RowsAffected)
+	case m.fetching:
+		summary = fmt.Sprintf("fetched %d rows, fetching more…", m.rowCount)
+	case m.hasMore:
+		summary = fmt.Sprintf("fetched %d rows, more available", m.rowCount)
+	case m.capped:
+		summary = fmt.Sprintf("fetched %d rows, stopped at the row limit", m.rowCount)
+	default:
+		summary = fmt.Sprintf("%d rows", m.rowCount)
+	}
+
+	summary = fmt.Sprintf("%s in %s", summary, m.duration.Round(time.Millisecond))
+
+	if staged := m.staged(); staged > 0 {
+		summary += fmt.Sprintf(" · %d staged changes (%s to review)", staged, m.screenProps.Keymap.ReviewChanges.Help().Key)
+	}
+
+	return summary
+}
+
+func (m *tab) view() string {
+	if m.running {
+		elapsed := time.Since(m.startedAt).Truncate(100 * time.Millisecond)
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Render(fmt.Sprintf("%s %s %s (%s to cancel)", m.spinner.View(), m.activity, elapsed, m.screenProps.Keymap.CancelQuery.Help().Key))
+	}
+
+	if m.err != nil {
+		content := renderError(m.err)
+		if m.table != nil {
+			content += "\n\n" + summaryStyle.Render(fmt.Sprintf("%s to return to the results", m.screenProps.Keymap.Cancel.Help().Key))
+		}
+
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Render(content)
+	}
+
+	if m.inserter != nil {
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Render(m.inserter.form.View())
+	}
+
+	if m.review != nil {
+		keys := fmt.Sprintf("%s to apply, %s to go back", m.screenProps.Keymap.Confirm.Help().Key, m.screenProps.Keymap.Cancel.Help().Key)
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Render(m.review.view(keys))
+	}
+
+	if m.detail != nil {
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Render(m.detail.view())
+	}
+
+	if m.table == nil {
+		content := "No results"
+		if m.results != nil {
+			content = summaryStyle.Render(m.summary())
+		}
+
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Render(content)
+	}
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Render(lipgloss.JoinVertical(
+			lipgloss.Left,
+			m.table.View(),
+			m.footer(),
+		))
+}
+
+// footer shows the editor while a cell is edited, and the summary otherwise.
+func (m *tab) footer() string {
+	if m.editor != nil {
+		return m.editor.View()
+	}
+
+	return summaryStyle.Render(m.summary())
+}
+
+func (m *tab) setSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	if m.table != nil {
+		newTable := m.table.WithTargetWidth(width)
+		m.table = &newTable
+	}
+
+	if m.detail != nil {
+		m.detail.setSize(width, height-1)
+	}
+
+	if m.review != nil {
+		m.review.viewport.Width = width
+		m.review.viewport.Height = height - 1
+	}
+}
+
+func (m *tab) focus() {
+	m.focused = true
+	if m.table != nil {
+		newTable := m.table.Focused(true).WithColumns(m.tableColumns())
+		m.table = &newTable
+	}
+}
+
+func (m *tab) blur() {
+	m.focused = false
+	if m.table != nil {
+		newTable := m.table.Focused(false).WithColumns(m.tableColumns())
+		m.table = &newTable
+	}
+}
+
+var (
+	summaryStyle      = lipgloss.NewStyle().Faint(true)
+	errorStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Bold(true)
+	errorLabelStyle   = lipgloss.NewStyle().Bold(true)
+	nullStyle         = lipgloss.NewStyle().Faint(true).Italic(true).Foreground(lipgloss.Color("#808080"))
+	numberStyle       = lipgloss.NewStyle().Align(lipgloss.Right)
+	stagedStyle       = lipgloss.NewStyle().Background(lipgloss.Color("#875F00")).Foreground(lipgloss.Color("#FFFFFF"))
+	deletedStyle      = lipgloss.NewStyle().Strikethrough(true).Foreground(lipgloss.Color("#FF5F87"))
+	cursorColumnStyle = lipgloss.NewStyle().Background(lipgloss.Color("#303030"))
+)
+
+// maxCellWidth is the number of characters a value is truncated to.
+const maxCellWidth = 40
+
+// cellText is the text of a value as shown in a cell. Values are kept to a
+// single line, and long values are truncated with a hint of their full size.
+func cellText(f database.FormattedValue) string {
+	text := strings.NewReplacer("\r\n", "↵", "\n", "↵", "\r", "↵", "\t", " ").Replace(f.Text)
+
+	runes := []rune(text)
+	if len(runes) <= maxCellWidth && f.Kind != database.KindBinary {
+		return text
+	}
+
+	hint := fmt.Sprintf(" (%s)", formatSize(f.Size))
+	if len(runes)+len(hint) <= maxCellWidth {
+		return text + hint
+	}
+
+	return string(runes[:maxCellWidth-len(hint)-1]) + "…" + hint
+}
+
+// formatSize describes a size in bytes, e.g. "12 B" or "3.4 KB".
+func formatSize(size int) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := unit, 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGT"[exp])
+}
+
+// renderError describes err in the style of psql, including the line of the
+// query an error position points at.
+func renderError(err error) string {
+	var qe *database.QueryError
+	if !errors.As(err, &qe) {
+		return errorStyle.Render(err.Error())
+	}
+
+	lines := []string{errorStyle.Render(qe.Message)}
+
+	if line, col, ok := qe.Line(); ok {
+		queryLines := strings.Split(qe.Query, "\n")
+		prefix := fmt.Sprintf("LINE %d: ", line+1)
+		lines = append(lines,
+			errorLabelStyle.Render(prefix)+queryLines[line],
+			strings.Repeat(" ", len(prefix)+col)+errorStyle.Render("^"),
+		)
+	}
+
+	if qe.Code != "" {
+		lines = append(lines, errorLabelStyle.Render("SQLSTATE: ")+qe.Code)
+	}
+
+	if qe.Detail != "" {
+		lines = append(lines, errorLabelStyle.Render("DETAIL: ")+qe.Detail)
+	}
+
+	if qe.Hint != "" {
+		lines = append(lines, errorLabelStyle.Render("HINT: ")+qe.Hint)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// columnKey is the table key of the column at index i.
+func columnKey(i int) string {
+	return strconv.Itoa(i)
+}
+
+// columnTitle is the header of a column. Postgres names columns computed by
+// an expression "?column?", which is replaced by the column's position.
+func columnTitle(i int, col database.Column) string {
+	if col.Name == "" || col.Name == "?column?" {
+		return fmt.Sprintf("column %d", i+1)
+	}
+
+	return col.Name
+}
+
+func calculateColumnWidths(columns []database.Column, rows [][]any) []int {
+	widths := make([]int, len(columns))
+
+	for i, col := range columns {
+		widths[i] = len(columnTitle(i, col))
+	}
+
+	for _, row := range rows {
+		for i, val := range row {
+			if i >= len(widths) {
+				continue
+			}
+
+			f := database.FormatValue(columns[i], val)
+			if w := lipgloss.Width(cellText(f)); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	for i := range widths {
+		widths[i] += 2
+	}
+
+	return widths
+}
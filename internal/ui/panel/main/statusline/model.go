@@ -1,6 +1,9 @@
 package statusline
 
 import (
+	"fmt"
+	"slices"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/davesavic/lazydb/internal/service/message"
@@ -9,10 +12,18 @@ import (
 var _ tea.Model = &Model{}
 
 type Model struct {
-	status  string
-	message string
-	width   int
-	height  int
+	status   string
+	message  string
+	session  string
+	sessions []string
+	// label, color and readOnly describe the active session's connection.
+	label    string
+	color    string
+	readOnly bool
+	// transactions holds the transaction state of each session.
+	transactions map[string]message.TransactionChangedMsg
+	width        int
+	height       int
 }
 
 // Init implements tea.Model.
@@ -26,6 +37,23 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case message.StatusUpdateMsg:
 		m.status = msg.Status
 		m.message = msg.Message
+
+	case message.ErrorMsg:
+		m.status = "ERROR"
+		m.message = msg.Err.Error()
+
+	case message.SessionsChangedMsg:
+		m.session = msg.Active
+		m.sessions = msg.Sessions
+
+		for name := range m.transactions {
+			if !slices.Contains(m.sessions, name) {
+				delete(m.transactions, name)
+			}
+		}
+
+	case message.TransactionChangedMsg:
+		m.transactions[msg.Session] = msg
 	}
 
 	return m, nil
@@ -47,16 +75,84 @@ func (m *Model) View() string {
 
 	statusMessage := lipgloss.NewStyle().Inherit(statusBarStyle)
 
+	sessionStyle := lipgloss.NewStyle().
+		Inherit(statusBarStyle).
+		Foreground(lipgloss.Color("#FFFDF5")).
+		Background(lipgloss.Color("#6124DF")).
+		Padding(0, 1)
+	if m.color != "" {
+		sessionStyle = sessionStyle.Background(lipgloss.Color(m.color))
+	}
+
+	transactionStyle := lipgloss.NewStyle().
+		Inherit(statusBarStyle).
+		Foreground(lipgloss.Color("#FFFDF5")).
+		Background(lipgloss.Color("#D75F00")).
+		Padding(0, 1)
+
+	status := statusStyle.Render(m.status)
+	session := sessionStyle.Render(m.sessionIndicator())
+
+	transaction := ""
+	if badge := m.transactionIndicator(); badge != "" {
+		transaction = transactionStyle.Render(badge)
+	}
+
 	bar := lipgloss.JoinHorizontal(lipgloss.Top,
-		statusStyle.Render(m.status),
-		statusMessage.Width(m.width-len(m.status)).Render(m.message),
+		status,
+		statusMessage.Width(m.width-lipgloss.Width(status)-lipgloss.Width(transaction)-lipgloss.Width(session)).Render(m.message),
+		transaction,
+		session,
 	)
 
 	return statusBarStyle.Width(m.width).MaxHeight(1).Render(bar)
 }
 
+// sessionIndicator describes the active session and its position among the
+// open sessions, e.g. "staging (2/3)".
+func (m *Model) sessionIndicator() string {
+	if m.session == "" {
+		return "not connected"
+	}
+
+	indicator := fmt.Sprintf("%s (%d/%d)", m.session, slices.Index(m.sessions, m.session)+1, len(m.sessions))
+	if m.label != "" {
+		indicator = m.label + " · " + indicator
+	}
+	if m.readOnly {
+		indicator += " · read only"
+	}
+
+	return indicator
+}
+
+// SetConnection sets the label, colour and read-only flag of the active
+// session's connection.
+func (m *Model) SetConnection(label, color string, readOnly bool) {
+	m.label = label
+	m.color = color
+	m.readOnly = readOnly
+}
+
+// transactionIndicator describes the active session's transaction, e.g.
+// "in transaction (3 statements)", or is empty if statements are committed
+// as they run.
+func (m *Model) transactionIndicator() string {
+	tx := m.transactions[m.session]
+	switch {
+	case tx.Open:
+		return fmt.Sprintf("in transaction (%d statements)", tx.Statements)
+	case tx.Manual:
+		return "manual transaction"
+	}
+
+	return ""
+}
+
 func NewModel() *Model {
-	return &Model{}
+	return &Model{
+		transactions: make(map[string]message.TransactionChangedMsg),
+	}
 }
 
 func (m *Model) SetSize(width, height int) {
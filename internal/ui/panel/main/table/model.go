@@ -1,53 +1,204 @@
 package table
 
 import (
-	"sort"
+	"fmt"
+	"log/slog"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/database"
 	"github.com/davesavic/lazydb/internal/service/message"
 	"github.com/davesavic/lazydb/internal/ui/common"
 )
 
 var _ tea.Model = &Model{}
 
+// groups are the kinds the objects inside a schema or table are grouped by,
+// in the order the groups are shown.
+var groups = []struct {
+	kind  database.ObjectKind
+	title string
+}{
+	{database.ObjectTable, "Tables"},
+	{database.ObjectView, "Views"},
+	{database.ObjectMaterializedView, "Materialized views"},
+	{database.ObjectSequence, "Sequences"},
+	{database.ObjectFunction, "Functions"},
+	{database.ObjectColumn, "Columns"},
+	{database.ObjectIndex, "Indexes"},
+	{database.ObjectConstraint, "Constraints"},
+}
+
+// Model browses the objects of the session's database as a tree of
+// databases, schemas, objects grouped by kind, and the columns, indexes and
+// constraints of tables. The objects inside a node are loaded when it is
+// first expanded.
 type Model struct {
 	id          string
 	screenProps *common.ScreenProps
 	list        list.Model
 	width       int
 	height      int
+
+	session string
+	root    *node
+
+	// filter hides the schemas whose names do not contain it, and filtering
+	// is set while it is typed.
+	filter    textinput.Model
+	filtering bool
+}
+
+// node is an object of the tree, or a group of the objects of one kind
+// inside a schema or table.
+type node struct {
+	object database.Object
+	// group is set for the nodes that group objects by kind, which are not
+	// objects themselves.
+	group    bool
+	parent   *node
+	children []*node
+	depth    int
+	expanded bool
+	// loaded is set once the objects inside the node have been loaded, and
+	// loading while they are.
+	loaded  bool
+	loading bool
+}
+
+// container reports whether the node has children, or may have once they
+// are loaded.
+func (n *node) container() bool {
+	return n.group || n.object.Kind.Container()
+}
+
+// path returns the objects from the top of the tree down to the node.
+func (n *node) path() []database.Object {
+	var path []database.Object
+	for ; n.parent != nil; n = n.parent {
+		if !n.group {
+			path = append([]database.Object{n.object}, path...)
+		}
+	}
+
+	return path
+}
+
+// child returns the node of object inside n, looking inside its groups.
+func (n *node) child(object database.Object) *node {
+	for _, c := range n.children {
+		if c.group {
+			if found := c.child(object); found != nil {
+				return found
+			}
+			continue
+		}
+
+		if c.object == object {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// setChildren replaces the nodes inside n with objects. Objects other than
+// databases and schemas are grouped by kind, and a single group is expanded
+// straight away.
+func (n *node) setChildren(objects []database.Object) {
+	n.children = nil
+
+	grouped := false
+	for _, object := range objects {
+		if object.Kind != database.ObjectDatabase && object.Kind != database.ObjectSchema {
+			grouped = true
+		}
+	}
+
+	if !grouped {
+		for _, object := range objects {
+			n.children = append(n.children, &node{object: object, parent: n, depth: n.depth + 1})
+		}
+		return
+	}
+
+	for _, g := range groups {
+		group := &node{group: true, parent: n, depth: n.depth + 1, loaded: true}
+		for _, object := range objects {
+			if object.Kind == g.kind {
+				group.children = append(group.children, &node{object: object, parent: group, depth: n.depth + 2})
+			}
+		}
+
+		if len(group.children) > 0 {
+			group.object.Name = fmt.Sprintf("%s (%d)", g.title, len(group.children))
+			n.children = append(n.children, group)
+		}
+	}
+
+	if len(n.children) == 1 {
+		n.children[0].expanded = true
+	}
 }
 
 type listItem struct {
-	name        string
-	description string
+	node *node
 }
 
 func (l listItem) Title() string {
-	return l.name
+	n := l.node
+
+	marker := "  "
+	switch {
+	case n.loading:
+		marker = "… "
+	case n.container() && n.expanded:
+		marker = "▾ "
+	case n.container():
+		marker = "▸ "
+	}
+
+	title := strings.Repeat("  ", n.depth) + marker + n.object.Name
+	if n.object.Detail != "" {
+		title += " " + n.object.Detail
+	}
+
+	return title
 }
 
 func (l listItem) Description() string {
-	return l.description
+	return ""
 }
 
 func (l listItem) FilterValue() string {
-	return l.name
+	return l.node.object.Name
 }
 
 func NewModel(props *common.ScreenProps) *Model {
-	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
-	l.Title = "Tables"
+	d := list.NewDefaultDelegate()
+	d.ShowDescription = false
+	d.SetSpacing(0)
+
+	l := list.New([]list.Item{}, d, 0, 0)
+	l.Title = "Objects"
 	l.SetShowHelp(false)
 	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+
+	filter := textinput.New()
+	filter.Prompt = "Schemas: "
+	filter.Placeholder = "filter"
 
 	return &Model{
 		id:          "tables",
 		screenProps: props,
 		list:        l,
+		root:        &node{depth: -1},
+		filter:      filter,
 	}
 }
 
@@ -62,21 +213,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case message.NewConnectionLoadedMsg:
-		tables, err := m.screenProps.DatabaseService.GetTables()
-		if err != nil {
-			return m, nil
-		}
+		m.session = msg.Session
+		m.root = &node{depth: -1}
+		m.refresh()
+
+		return m, m.load(m.root)
 
-		sort.Strings(tables)
+	case message.ObjectsLoadedMsg:
+		return m, m.loaded(msg)
 
-		items := make([]list.Item, 0, len(tables))
-		for _, t := range tables {
-			items = append(items, listItem{name: t})
+	case tea.KeyMsg:
+		if m.filtering {
+			return m, m.updateFilter(msg)
 		}
 
-		m.list.SetItems(items)
+		selected, _ := m.list.SelectedItem().(listItem)
 
-	case tea.KeyMsg:
 		switch {
 		case msg.String() == "q":
 			return m, nil
@@ -88,6 +240,27 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, m.screenProps.MessageManager.NewNavigateDirectionCmd("up", m.id))
 		case key.Matches(msg, m.screenProps.Keymap.NavigateLeft):
 			cmds = append(cmds, m.screenProps.MessageManager.NewNavigateDirectionCmd("left", m.id))
+		case key.Matches(msg, m.screenProps.Keymap.ExpandObject):
+			if selected.node != nil {
+				return m, m.expand(selected.node)
+			}
+			return m, nil
+		case key.Matches(msg, m.screenProps.Keymap.CollapseObject):
+			if selected.node != nil {
+				m.collapse(selected.node)
+			}
+			return m, nil
+		case key.Matches(msg, m.screenProps.Keymap.FilterSchemas):
+			m.filtering = true
+			m.resize()
+			return m, m.filter.Focus()
+		case key.Matches(msg, m.screenProps.Keymap.ReloadObjects):
+			return m, m.reload(selected.node)
+		case key.Matches(msg, m.screenProps.Keymap.ShowStructure):
+			if selected.node != nil {
+				return m, m.showStructure(selected.node)
+			}
+			return m, nil
 		}
 	}
 
@@ -98,19 +271,219 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// load loads the objects inside n.
+func (m *Model) load(n *node) tea.Cmd {
+	if m.session == "" || n.loading {
+		return nil
+	}
+
+	n.loading = true
+	m.refresh()
+
+	return m.screenProps.MessageManager.NewLoadObjectsCmd(m.session, n.path())
+}
+
+// loaded puts the objects that were loaded inside their node, and expands
+// it.
+func (m *Model) loaded(msg message.ObjectsLoadedMsg) tea.Cmd {
+	if msg.Session != m.session {
+		return nil
+	}
+
+	n := m.root
+	for _, object := range msg.Path {
+		if n = n.child(object); n == nil {
+			return nil
+		}
+	}
+
+	n.loading = false
+	if msg.Err != nil {
+		slog.Error("Tables.loaded", "session", msg.Session, "path", msg.Path, "error", msg.Err)
+		m.refresh()
+		return message.NewStatusUpdateCmd("OBJECTS", fmt.Sprintf("Could not load objects: %v", msg.Err))
+	}
+
+	n.setChildren(msg.Objects)
+	n.loaded = true
+	n.expanded = true
+	m.refresh()
+
+	return nil
+}
+
+// expand shows the nodes inside n, loading them the first time. Expanding
+// an expanded node moves to the first node inside it.
+func (m *Model) expand(n *node) tea.Cmd {
+	if !n.container() {
+		return nil
+	}
+
+	if n.expanded {
+		if len(n.children) > 0 {
+			m.list.Select(m.list.Index() + 1)
+		}
+		return nil
+	}
+
+	if !n.loaded {
+		return m.load(n)
+	}
+
+	n.expanded = true
+	m.refresh()
+
+	return nil
+}
+
+// collapse hides the nodes inside n, or moves to the node n is inside if it
+// is not expanded.
+func (m *Model) collapse(n *node) {
+	if n.container() && n.expanded {
+		n.expanded = false
+		m.refresh()
+		return
+	}
+
+	for i, item := range m.list.Items() {
+		if item.(listItem).node == n.parent {
+			m.list.Select(i)
+			return
+		}
+	}
+}
+
+// showStructure shows the structure of the table or view n is part of. Any
+// other node is expanded or collapsed instead.
+func (m *Model) showStructure(n *node) tea.Cmd {
+	table := n
+	for table != nil && !isRelation(table) {
+		table = table.parent
+	}
+
+	if table == nil {
+		if n.expanded {
+			m.collapse(n)
+			return nil
+		}
+		return m.expand(n)
+	}
+
+	var schema string
+	for _, object := range table.path() {
+		if object.Kind == database.ObjectSchema {
+			schema = object.Name
+		}
+	}
+
+	return m.screenProps.MessageManager.NewLoadStructureCmd(m.session, schema, table.object.Name)
+}
+
+// isRelation reports whether n is a table or view.
+func isRelation(n *node) bool {
+	switch n.object.Kind {
+	case database.ObjectTable, database.ObjectView, database.ObjectMaterializedView:
+		return !n.group
+	}
+
+	return false
+}
+
+// reload loads the objects inside the object n is part of again, or the
+// whole tree if there is none.
+func (m *Model) reload(n *node) tea.Cmd {
+	for n != nil && (n.group || !n.container()) {
+		n = n.parent
+	}
+	if n == nil {
+		n = m.root
+	}
+
+	n.children = nil
+	n.loaded = false
+	n.expanded = false
+
+	return m.load(n)
+}
+
+// updateFilter edits the schema filter. Confirming keeps it, cancelling
+// clears it.
+func (m *Model) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.String() == "enter":
+		m.filtering = false
+		m.filter.Blur()
+		m.resize()
+		return nil
+	case key.Matches(msg, m.screenProps.Keymap.Cancel):
+		m.filtering = false
+		m.filter.Blur()
+		m.filter.SetValue("")
+		m.resize()
+		m.refresh()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.refresh()
+
+	return cmd
+}
+
+// refresh lists the nodes that are shown: those inside expanded nodes, less
+// the schemas the filter hides.
+func (m *Model) refresh() {
+	filter := strings.ToLower(m.filter.Value())
+
+	var items []list.Item
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, c := range n.children {
+			if c.object.Kind == database.ObjectSchema && !strings.Contains(strings.ToLower(c.object.Name), filter) {
+				continue
+			}
+
+			items = append(items, listItem{node: c})
+			if c.expanded {
+				walk(c)
+			}
+		}
+	}
+	walk(m.root)
+
+	m.list.SetItems(items)
+}
+
 // View implements tea.Model.
 func (m *Model) View() string {
+	view := m.list.View()
+	if m.filtering || m.filter.Value() != "" {
+		view = lipgloss.JoinVertical(lipgloss.Left, m.filter.View(), view)
+	}
+
 	return lipgloss.
 		NewStyle().
 		Width(m.width).
 		Height(m.height).
-		Render(m.list.View())
+		Render(view)
 }
 
 func (m *Model) SetSize(width, height int) {
 	m.width = width
 	m.height = height
-	m.list.SetSize(width, height)
+	m.resize()
+}
+
+// resize fits the list under the filter, when it is shown.
+func (m *Model) resize() {
+	height := m.height
+	if m.filtering || m.filter.Value() != "" {
+		height--
+	}
+
+	m.filter.Width = max(m.width-lipgloss.Width(m.filter.Prompt)-1, 0)
+	m.list.SetSize(m.width, max(height, 0))
 }
 
 func (m *Model) Focus() {
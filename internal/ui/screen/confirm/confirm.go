@@ -0,0 +1,101 @@
+package confirm
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/message"
+	"github.com/davesavic/lazydb/internal/ui/common"
+)
+
+// Confirm asks the user to confirm an action before its message is sent.
+type Confirm struct {
+	width       int
+	height      int
+	screenProps *common.ScreenProps
+
+	form      *huh.Form
+	confirmed bool
+	// msg is sent if the action is confirmed.
+	msg tea.Msg
+}
+
+func NewConfirm(props *common.ScreenProps) *Confirm {
+	return &Confirm{
+		screenProps: props,
+	}
+}
+
+// Init implements Screen.
+func (c *Confirm) Init() tea.Cmd {
+	if c.form == nil {
+		return nil
+	}
+
+	return c.form.Init()
+}
+
+// Update implements Screen.
+func (c *Confirm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+		return c, nil
+
+	case message.ConfirmMsg:
+		c.msg = msg.Msg
+		c.confirmed = false
+		c.form = huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title(msg.Prompt).
+					Affirmative("Yes").
+					Negative("No").
+					Value(&c.confirmed),
+			),
+		).WithShowHelp(false)
+
+		return c, c.screenProps.MessageManager.NewChangeScreenCmd(message.ScreenNameConfirm)
+
+	case tea.KeyMsg:
+		if key.Matches(msg, c.screenProps.Keymap.Cancel) {
+			c.form = nil
+			return c, c.screenProps.MessageManager.NewPreviousScreenCmd()
+		}
+	}
+
+	if c.form == nil {
+		return c, nil
+	}
+
+	newForm, cmd := c.form.Update(msg)
+	if f, ok := newForm.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State != huh.StateCompleted {
+		return c, cmd
+	}
+
+	c.form = nil
+	if !c.confirmed {
+		return c, c.screenProps.MessageManager.NewPreviousScreenCmd()
+	}
+
+	confirmed := c.msg
+	return c, tea.Sequence(
+		c.screenProps.MessageManager.NewPreviousScreenCmd(),
+		func() tea.Msg { return confirmed },
+	)
+}
+
+// View implements Screen.
+func (c *Confirm) View() string {
+	if c.form == nil {
+		return ""
+	}
+
+	return lipgloss.Place(c.width, c.height, lipgloss.Center, lipgloss.Center, c.form.View())
+}
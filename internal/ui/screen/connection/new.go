@@ -1,11 +1,15 @@
 package connection
 
 import (
+	"errors"
 	"log/slog"
+	"regexp"
+	"slices"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
+	"github.com/davesavic/lazydb/internal/service/database"
 	"github.com/davesavic/lazydb/internal/service/message"
 	"github.com/davesavic/lazydb/internal/ui/common"
 )
@@ -17,6 +21,10 @@ type Connection struct {
 	Database string
 	User     string
 	Password string
+	Path     string
+	ReadOnly bool
+	Label    string
+	Color    string
 }
 
 type NewConnection struct {
@@ -33,22 +41,71 @@ func NewNewConnection(props *common.ScreenProps) *NewConnection {
 
 	return &NewConnection{
 		screenProps: props,
+		form:        newConnectionForm(result),
+		result:      result,
+	}
+}
 
-		form: huh.NewForm(
-			huh.NewGroup(
-				huh.NewSelect[string]().Title("Type").Options(
-					huh.Option[string]{Key: "postgres", Value: "Postgres"},
-				).Value(&result.Type),
-				huh.NewInput().Title("Host").Placeholder("localhost").Value(&result.Host),
-				huh.NewInput().Title("Port").Placeholder("5432").Value(&result.Port),
-				huh.NewInput().Title("Database").Placeholder("postgres").Value(&result.Database),
-				huh.NewInput().Title("User").Placeholder("postgres").Value(&result.User),
-				huh.NewInput().Title("Password").Placeholder("password").Value(&result.Password),
-			),
+// newConnectionForm builds the form that fills in result.
+func newConnectionForm(result *Connection) *huh.Form {
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().Title("Type").Options(
+				typeOptions()...,
+			).Value(&result.Type),
 		),
+		// Server based connections
+		huh.NewGroup(
+			huh.NewInput().Title("Host").Placeholder("localhost").Value(&result.Host),
+			huh.NewInput().Title("Port").Placeholder("5432").Value(&result.Port),
+			huh.NewInput().Title("Database").Placeholder("postgres").Value(&result.Database),
+			huh.NewInput().Title("User").Placeholder("postgres").Value(&result.User),
+			huh.NewInput().Title("Password").Placeholder("password").Value(&result.Password),
+		).WithHideFunc(func() bool {
+			return result.Type == database.SQLiteDriverName
+		}),
+		// File based connections
+		huh.NewGroup(
+			huh.NewInput().Title("Path").Placeholder("./database.sqlite").Value(&result.Path),
+		).WithHideFunc(func() bool {
+			// Plugins may need either a server or a path, so they get both.
+			return result.Type == database.PostgresDriverName || result.Type == database.MySQLDriverName
+		}),
+		huh.NewGroup(
+			huh.NewConfirm().Title("Read only").Description("Refuse statements that change the database").Value(&result.ReadOnly),
+			huh.NewInput().Title("Label").Placeholder("production").Value(&result.Label),
+			huh.NewInput().Title("Color").Description("Hex colour or ANSI colour number").Placeholder("#FF0000").Validate(validateColor).Value(&result.Color),
+		),
+	)
+}
 
-		result: result,
+// colorPattern matches a hex colour or an ANSI colour number.
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+
+func validateColor(color string) error {
+	if color != "" && !colorPattern.MatchString(color) {
+		return errors.New("not a hex colour or ANSI colour number")
 	}
+
+	return nil
+}
+
+// typeOptions lists the built in drivers first, followed by any drivers
+// registered by plugins.
+func typeOptions() []huh.Option[string] {
+	options := []huh.Option[string]{
+		{Key: "Postgres", Value: database.PostgresDriverName},
+		{Key: "MySQL / MariaDB", Value: database.MySQLDriverName},
+		{Key: "SQLite", Value: database.SQLiteDriverName},
+	}
+
+	for _, name := range database.Drivers() {
+		if !slices.ContainsFunc(options, func(o huh.Option[string]) bool { return o.Value == name }) {
+			options = append(options, huh.Option[string]{Key: name, Value: name})
+		}
+	}
+
+	return options
 }
 
 // Init implements Screen.
@@ -73,7 +130,11 @@ func (n *NewConnection) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if n.form.State == huh.StateCompleted {
 		slog.Debug("NewConnection.Update", "result", n.result)
 		copiedResult := *n.result
+
+		// Start over with a fresh form so the next connection can be added.
 		n.result = &Connection{}
+		n.form = newConnectionForm(n.result)
+
 		return n, n.screenProps.MessageManager.NewAddConnectionCmd(message.NewAddConnectionMsg{
 			Type:     copiedResult.Type,
 			Host:     copiedResult.Host,
@@ -81,6 +142,10 @@ func (n *NewConnection) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			Database: copiedResult.Database,
 			User:     copiedResult.User,
 			Password: copiedResult.Password,
+			Path:     copiedResult.Path,
+			ReadOnly: copiedResult.ReadOnly,
+			Label:    copiedResult.Label,
+			Color:    copiedResult.Color,
 		})
 	}
 
@@ -0,0 +1,160 @@
+package historyscreen
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/history"
+	"github.com/davesavic/lazydb/internal/service/message"
+	"github.com/davesavic/lazydb/internal/ui/common"
+)
+
+var boxStyle = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#FF00FF")).Padding(0, 1)
+
+// History lists the queries that were run, newest first, to load one back
+// into the editor or run it again. The list is searched with a fuzzy filter.
+type History struct {
+	width       int
+	height      int
+	screenProps *common.ScreenProps
+
+	list list.Model
+}
+
+type listItem struct {
+	entry history.Entry
+}
+
+// Title is the query on a single line.
+func (l listItem) Title() string {
+	return strings.Join(strings.Fields(l.entry.Query), " ")
+}
+
+func (l listItem) Description() string {
+	outcome := fmt.Sprintf("%d rows", l.entry.Rows)
+	if l.entry.More {
+		outcome = fmt.Sprintf("%d+ rows", l.entry.Rows)
+	}
+	if l.entry.Error != "" {
+		outcome = "failed: " + strings.Join(strings.Fields(l.entry.Error), " ")
+	}
+
+	return fmt.Sprintf("%s · %s · %s · %s",
+		l.entry.Connection,
+		l.entry.Time.Local().Format("2006-01-02 15:04:05"),
+		l.entry.Duration.Round(time.Millisecond),
+		outcome)
+}
+
+func (l listItem) FilterValue() string {
+	return l.entry.Connection + " " + l.Title()
+}
+
+func NewHistory(props *common.ScreenProps) *History {
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Query history"
+	l.SetShowStatusBar(false)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "Load query")),
+			key.NewBinding(key.WithKeys(props.Keymap.ExecuteQuery.Keys()...), key.WithHelp(props.Keymap.ExecuteQuery.Help().Key, "Run query")),
+		}
+	}
+
+	return &History{
+		screenProps: props,
+		list:        l,
+	}
+}
+
+// Init implements Screen.
+func (h *History) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements Screen.
+func (h *History) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h.width = msg.Width
+		h.height = msg.Height
+		h.resize()
+		return h, nil
+
+	case message.ShowHistoryMsg:
+		return h, h.show()
+
+	case tea.KeyMsg:
+		// Cancel clears the filter first, if one has been typed.
+		if key.Matches(msg, h.screenProps.Keymap.Cancel) && h.list.FilterValue() == "" {
+			return h, h.screenProps.MessageManager.NewPreviousScreenCmd()
+		}
+
+		if h.list.FilterState() == list.Filtering {
+			break
+		}
+
+		run := key.Matches(msg, h.screenProps.Keymap.ExecuteQuery)
+		if msg.String() == "enter" || run {
+			selected, ok := h.list.SelectedItem().(listItem)
+			if !ok {
+				return h, nil
+			}
+
+			return h, tea.Sequence(
+				h.screenProps.MessageManager.NewPreviousScreenCmd(),
+				h.screenProps.MessageManager.NewLoadQueryCmd(selected.entry.Query, run),
+			)
+		}
+	}
+
+	newList, cmd := h.list.Update(msg)
+	h.list = newList
+
+	return h, cmd
+}
+
+// show loads the history and switches to it, ready to search.
+func (h *History) show() tea.Cmd {
+	entries, err := h.screenProps.History.Entries()
+	if err != nil {
+		slog.Error("History.show", "error", err)
+		return h.screenProps.MessageManager.NewErrorCmd(err)
+	}
+	if len(entries) == 0 {
+		return message.NewStatusUpdateCmd("HISTORY", "No queries have been run yet")
+	}
+
+	items := make([]list.Item, len(entries))
+	for i, entry := range entries {
+		items[i] = listItem{entry: entry}
+	}
+
+	h.list.ResetFilter()
+	h.list.Select(0)
+	cmd := h.list.SetItems(items)
+
+	// Start filtering straight away, as if the filter key was pressed.
+	filter := h.list.KeyMap.Filter.Keys()[0]
+	h.list, _ = h.list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(filter)})
+
+	return tea.Batch(cmd, h.screenProps.MessageManager.NewChangeScreenCmd(message.ScreenNameHistory))
+}
+
+// resize fits the list in a box that covers most of the screen.
+func (h *History) resize() {
+	width := h.width*4/5 - boxStyle.GetHorizontalFrameSize()
+	height := h.height*4/5 - boxStyle.GetVerticalFrameSize()
+	h.list.SetSize(max(width, 0), max(height, 0))
+}
+
+// View implements Screen.
+func (h *History) View() string {
+	return lipgloss.Place(h.width, h.height, lipgloss.Center, lipgloss.Center, boxStyle.Render(h.list.View()))
+}
@@ -2,7 +2,10 @@ package mainscreen
 
 import (
 	"log/slog"
+	"slices"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/davesavic/lazydb/internal/service/message"
@@ -18,30 +21,51 @@ type Main struct {
 	width  int
 	height int
 
+	screenProps    *common.ScreenProps
 	messageManager *message.Manager
 	activePanel    PanelID
 	navMap         NavigationMap
 
 	// Panels
 	connectionModel *connection.Model
-	queryModel      *query.Model
-	resultsModel    *result.Model
-	tablesModel     *table.Model
 	statusModel     *statusline.Model
+
+	// Each open session has its own workspace, keyed by session name. The
+	// workspace of the active session is also held in ws.
+	workspaces map[string]*workspace
+	ws         *workspace
+}
+
+// workspace holds the panels that belong to a single session.
+type workspace struct {
+	queryModel   *query.Model
+	resultsModel *result.Model
+	tablesModel  *table.Model
+}
+
+func newWorkspace(props *common.ScreenProps) *workspace {
+	return &workspace{
+		queryModel:   query.NewModel(props),
+		resultsModel: result.NewModel(props),
+		tablesModel:  table.NewModel(props),
+	}
 }
 
 func NewMain(props *common.ScreenProps) *Main {
 	slog.Debug("NewMain")
 
+	// The workspace keyed by "" is used until a connection is loaded.
+	ws := newWorkspace(props)
+
 	return &Main{
 		activePanel:     PanelConnection,
 		navMap:          NewNavigationMap(),
+		screenProps:     props,
 		messageManager:  props.MessageManager,
 		connectionModel: connection.NewModel(props),
-		queryModel:      query.NewModel(props),
-		resultsModel:    result.NewModel(props),
-		tablesModel:     table.NewModel(props),
 		statusModel:     statusline.NewModel(),
+		workspaces:      map[string]*workspace{"": ws},
+		ws:              ws,
 	}
 }
 
@@ -51,36 +75,186 @@ func (m *Main) Init() tea.Cmd {
 
 	return tea.Batch(
 		m.connectionModel.Init(),
-		m.queryModel.Init(),
-		m.resultsModel.Init(),
-		m.tablesModel.Init(),
+		m.ws.queryModel.Init(),
+		m.ws.resultsModel.Init(),
+		m.ws.tablesModel.Init(),
 		m.statusModel.Init(),
 	)
 }
 
+// workspaceFor returns the workspace of the named session, creating it if
+// needed. The first session adopts the workspace used before connecting, so
+// a query typed beforehand is kept.
+func (m *Main) workspaceFor(session string) *workspace {
+	if ws, ok := m.workspaces[session]; ok {
+		return ws
+	}
+
+	ws, ok := m.workspaces[""]
+	if ok {
+		delete(m.workspaces, "")
+	} else {
+		ws = newWorkspace(m.screenProps)
+	}
+
+	m.workspaces[session] = ws
+	m.resizeComponents(m.width, m.height)
+
+	return ws
+}
+
+// switchWorkspace makes the named session's workspace the active one and
+// drops the workspaces of sessions that are no longer open.
+func (m *Main) switchWorkspace(msg message.SessionsChangedMsg) {
+	for name := range m.workspaces {
+		if name != "" && !slices.Contains(msg.Sessions, name) {
+			delete(m.workspaces, name)
+		}
+	}
+
+	m.ws.queryModel.Blur()
+	m.ws.resultsModel.Blur()
+	m.ws.tablesModel.Blur()
+
+	if msg.Active == "" {
+		ws, ok := m.workspaces[""]
+		if !ok {
+			ws = newWorkspace(m.screenProps)
+			m.workspaces[""] = ws
+			m.resizeComponents(m.width, m.height)
+		}
+		m.ws = ws
+	} else {
+		m.ws = m.workspaceFor(msg.Active)
+	}
+
+	if s := m.screenProps.SessionManager.Active(); s != nil {
+		m.statusModel.SetConnection(s.Config.Label, s.Config.Color, s.Config.ReadOnly)
+	} else {
+		m.statusModel.SetConnection("", "", false)
+	}
+
+	m.focusPanel(m.activePanel)
+}
+
 // Update implements Screen.
 func (m *Main) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	slog.Debug("Main.Update")
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key.Matches(msg, m.screenProps.Keymap.ShowSnippets) {
+			return m, m.messageManager.NewShowSnippetsCmd()
+		}
+
 	case message.NewConnectionLoadedMsg:
-		newTable, cmd := m.tablesModel.Update(msg)
-		m.tablesModel = newTable.(*table.Model)
+		ws := m.workspaceFor(msg.Session)
+		newTable, cmd := ws.tablesModel.Update(msg)
+		ws.tablesModel = newTable.(*table.Model)
 		cmds = append(cmds, cmd)
 		cmds = append(cmds, m.messageManager.NewNavigateDirectionCmd(message.DirectionDown, "connections"))
 
+	case message.SessionsChangedMsg:
+		m.switchWorkspace(msg)
+
+		newConnectionPanel, cmd := m.connectionModel.Update(msg)
+		m.connectionModel = newConnectionPanel.(*connection.Model)
+		cmds = append(cmds, cmd)
+
+		newStatus, cmd := m.statusModel.Update(msg)
+		m.statusModel = newStatus.(*statusline.Model)
+		cmds = append(cmds, cmd)
+
+	case message.StatusUpdateMsg, message.TransactionChangedMsg:
+		newStatus, cmd := m.statusModel.Update(msg)
+		m.statusModel = newStatus.(*statusline.Model)
+		cmds = append(cmds, cmd)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.resizeComponents(m.width, m.height)
 
+	case message.QueryStartedMsg:
+		return m, m.updateSessionResults(msg.Session, msg)
+
 	case message.QueryExecutedMsg:
 		slog.Debug("Main.Update.QueryExecutedMsg", "msg", msg)
-		newResults, cmd := m.resultsModel.Update(msg)
-		m.resultsModel = newResults.(*result.Model)
+		return m, m.updateSessionResults(msg.Session, msg)
+
+	case message.QueryCancelledMsg:
+		return m, m.updateSessionResults(msg.Session, msg)
+
+	case message.RowsFetchedMsg:
+		return m, m.updateSessionResults(msg.Session, msg)
+
+	case message.ChangesAppliedMsg:
+		return m, m.updateSessionResults(msg.Session, msg)
+
+	case message.CatalogLoadedMsg:
+		ws, ok := m.workspaces[msg.Session]
+		if !ok {
+			return m, nil
+		}
+
+		newQuery, cmd := ws.queryModel.Update(msg)
+		ws.queryModel = newQuery.(*query.Model)
+		return m, cmd
+
+	case message.ObjectsLoadedMsg:
+		ws, ok := m.workspaces[msg.Session]
+		if !ok {
+			return m, nil
+		}
+
+		newTable, cmd := ws.tablesModel.Update(msg)
+		ws.tablesModel = newTable.(*table.Model)
+		return m, cmd
+
+	case message.LoadQueryMsg:
+		m.connectionModel.Blur()
+		m.ws.resultsModel.Blur()
+		m.ws.tablesModel.Blur()
+		m.activePanel = PanelQuery
+		m.focusPanel(m.activePanel)
+
+		newQuery, cmd := m.ws.queryModel.Update(msg)
+		m.ws.queryModel = newQuery.(*query.Model)
+		return m, cmd
+
+	case message.ErrorMsg:
+		ws := m.ws
+		if msg.Session != "" {
+			ws = m.workspaces[msg.Session]
+		}
+
+		newStatus, cmd := m.statusModel.Update(msg)
+		m.statusModel = newStatus.(*statusline.Model)
+		cmds = append(cmds, cmd)
+
+		if ws == nil {
+			return m, tea.Batch(cmds...)
+		}
+
+		newResults, cmd := ws.resultsModel.Update(msg)
+		ws.resultsModel = newResults.(*result.Model)
+		cmds = append(cmds, cmd)
+
+		newQuery, cmd := ws.queryModel.Update(msg)
+		ws.queryModel = newQuery.(*query.Model)
 		cmds = append(cmds, cmd)
 
+		return m, tea.Batch(cmds...)
+
+	case spinner.TickMsg:
+		for _, ws := range m.workspaces {
+			newResults, cmd := ws.resultsModel.Update(msg)
+			ws.resultsModel = newResults.(*result.Model)
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
+
 	case message.NavigateDirectionMsg:
 		if PanelID(msg.Source) != m.activePanel {
 			return m, nil
@@ -94,20 +268,11 @@ func (m *Main) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.activePanel = newPanel
 
 		m.connectionModel.Blur()
-		m.queryModel.Blur()
-		m.resultsModel.Blur()
-		m.tablesModel.Blur()
-
-		switch newPanel {
-		case PanelConnection:
-			m.connectionModel.Focus()
-		case PanelQuery:
-			m.queryModel.Focus()
-		case PanelResults:
-			m.resultsModel.Focus()
-		case PanelTables:
-			m.tablesModel.Focus()
-		}
+		m.ws.queryModel.Blur()
+		m.ws.resultsModel.Blur()
+		m.ws.tablesModel.Blur()
+
+		m.focusPanel(newPanel)
 	}
 
 	switch m.activePanel {
@@ -117,24 +282,51 @@ func (m *Main) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 
 	case PanelQuery:
-		newQueryPanel, cmd := m.queryModel.Update(msg)
-		m.queryModel = newQueryPanel.(*query.Model)
+		newQueryPanel, cmd := m.ws.queryModel.Update(msg)
+		m.ws.queryModel = newQueryPanel.(*query.Model)
 		cmds = append(cmds, cmd)
 
 	case PanelResults:
-		newResultsPanel, cmd := m.resultsModel.Update(msg)
-		m.resultsModel = newResultsPanel.(*result.Model)
+		newResultsPanel, cmd := m.ws.resultsModel.Update(msg)
+		m.ws.resultsModel = newResultsPanel.(*result.Model)
 		cmds = append(cmds, cmd)
 
 	case PanelTables:
-		newTablesPanel, cmd := m.tablesModel.Update(msg)
-		m.tablesModel = newTablesPanel.(*table.Model)
+		newTablesPanel, cmd := m.ws.tablesModel.Update(msg)
+		m.ws.tablesModel = newTablesPanel.(*table.Model)
 		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// updateSessionResults sends msg to the results panel of the named session's
+// workspace, whether or not it is the active one.
+func (m *Main) updateSessionResults(session string, msg tea.Msg) tea.Cmd {
+	ws, ok := m.workspaces[session]
+	if !ok {
+		return nil
+	}
+
+	newResults, cmd := ws.resultsModel.Update(msg)
+	ws.resultsModel = newResults.(*result.Model)
+
+	return cmd
+}
+
+func (m *Main) focusPanel(panel PanelID) {
+	switch panel {
+	case PanelConnection:
+		m.connectionModel.Focus()
+	case PanelQuery:
+		m.ws.queryModel.Focus()
+	case PanelResults:
+		m.ws.resultsModel.Focus()
+	case PanelTables:
+		m.ws.tablesModel.Focus()
+	}
+}
+
 // View implements Screen.
 func (m *Main) View() string {
 	return m.renderMainScreen()
@@ -162,11 +354,25 @@ func (m *Main) renderMainScreen() string {
 	)
 }
 
+// stylePane draws a border around a pane, highlighting the active one. The
+// borders of a connection with a colour are drawn in it, with a thick border
+// marking the active pane instead.
 func (m *Main) stylePane(content string, active bool) string {
 	style := lipgloss.NewStyle().
 		BorderStyle(lipgloss.RoundedBorder())
 
-	if active {
+	var color string
+	if s := m.screenProps.SessionManager.Active(); s != nil {
+		color = s.Config.Color
+	}
+
+	switch {
+	case color != "":
+		style = style.BorderForeground(lipgloss.Color(color))
+		if active {
+			style = style.BorderStyle(lipgloss.ThickBorder())
+		}
+	case active:
 		style = style.BorderForeground(lipgloss.Color("#FF00FF"))
 	}
 
@@ -175,7 +381,7 @@ func (m *Main) stylePane(content string, active bool) string {
 
 func (m *Main) renderConnectionSection() string {
 	connectionsView := m.stylePane(m.connectionModel.View(), m.activePanel == PanelConnection)
-	tablesView := m.stylePane(m.tablesModel.View(), m.activePanel == PanelTables)
+	tablesView := m.stylePane(m.ws.tablesModel.View(), m.activePanel == PanelTables)
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -185,8 +391,8 @@ func (m *Main) renderConnectionSection() string {
 }
 
 func (m *Main) renderQuerySection() string {
-	queryView := m.stylePane(m.queryModel.View(), m.activePanel == PanelQuery)
-	resultsView := m.stylePane(m.resultsModel.View(), m.activePanel == PanelResults)
+	queryView := m.stylePane(m.ws.queryModel.View(), m.activePanel == PanelQuery)
+	resultsView := m.stylePane(m.ws.resultsModel.View(), m.activePanel == PanelResults)
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -196,6 +402,11 @@ func (m *Main) renderQuerySection() string {
 }
 
 func (m *Main) resizeComponents(width, height int) {
+	// Nothing to size until the first window size message arrives.
+	if width == 0 || height == 0 {
+		return
+	}
+
 	// Add padding
 	fullWidth := width
 
@@ -225,9 +436,11 @@ func (m *Main) resizeComponents(width, height int) {
 
 	// Update component dimensions
 	m.connectionModel.SetSize(leftWidth, connectionHeight)
-	m.tablesModel.SetSize(leftWidth, tableHeight)
-	m.queryModel.SetSize(rightWidth, queryHeight)
-	m.resultsModel.SetSize(rightWidth, resultsHeight)
+	for _, ws := range m.workspaces {
+		ws.tablesModel.SetSize(leftWidth, tableHeight)
+		ws.queryModel.SetSize(rightWidth, queryHeight)
+		ws.resultsModel.SetSize(rightWidth, resultsHeight)
+	}
 	m.statusModel.SetSize(fullWidth, 1)
 }
 
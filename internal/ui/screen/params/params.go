@@ -0,0 +1,272 @@
+package params
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/database"
+	"github.com/davesavic/lazydb/internal/service/history"
+	"github.com/davesavic/lazydb/internal/service/message"
+	"github.com/davesavic/lazydb/internal/ui/common"
+)
+
+// maxPreviewLines is the number of lines of the statements shown above the
+// form.
+const maxPreviewLines = 8
+
+// The types a parameter's value can be given as.
+const (
+	typeText    = "text"
+	typeNumber  = "number"
+	typeBoolean = "boolean"
+	typeNull    = "null"
+)
+
+var (
+	boxStyle   = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#FF00FF")).Padding(0, 1)
+	titleStyle = lipgloss.NewStyle().Bold(true).MarginBottom(1)
+)
+
+// Params asks for the values of the parameters of statements before they
+// run, with the values bound as arguments. The values given for a script are
+// offered again the next time it runs.
+type Params struct {
+	width       int
+	height      int
+	screenProps *common.ScreenProps
+
+	form       *huh.Form
+	statements []database.Statement
+	// names holds the parameters of the statements, and values the value of
+	// each.
+	names  []string
+	values []history.ParamValue
+}
+
+func NewParams(props *common.ScreenProps) *Params {
+	return &Params{
+		screenProps: props,
+	}
+}
+
+// Init implements Screen.
+func (p *Params) Init() tea.Cmd {
+	if p.form == nil {
+		return nil
+	}
+
+	return p.form.Init()
+}
+
+// Update implements Screen.
+func (p *Params) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+		if p.form != nil {
+			p.form = p.form.WithWidth(p.formWidth())
+		}
+		return p, nil
+
+	case message.PromptParamsMsg:
+		return p, p.prompt(msg.Statements)
+
+	case tea.KeyMsg:
+		if key.Matches(msg, p.screenProps.Keymap.Cancel) {
+			p.form = nil
+			return p, p.screenProps.MessageManager.NewPreviousScreenCmd()
+		}
+	}
+
+	if p.form == nil {
+		return p, nil
+	}
+
+	newForm, cmd := p.form.Update(msg)
+	if f, ok := newForm.(*huh.Form); ok {
+		p.form = f
+	}
+
+	if p.form.State != huh.StateCompleted {
+		return p, cmd
+	}
+
+	p.form = nil
+	return p, tea.Sequence(
+		p.screenProps.MessageManager.NewPreviousScreenCmd(),
+		p.run(),
+	)
+}
+
+// prompt opens a form for the values of the parameters of statements, or
+// runs them straight away if they have none.
+func (p *Params) prompt(statements []database.Statement) tea.Cmd {
+	session := p.screenProps.SessionManager.Active()
+	if session == nil {
+		return message.NewStatusUpdateCmd("NO CONNECTION", "Connect to a database to run queries")
+	}
+	dialect := session.Database.Dialect()
+
+	p.statements = statements
+	p.names = nil
+	for _, stmt := range statements {
+		for _, name := range dialect.Params(stmt.SQL) {
+			if !slices.Contains(p.names, name) {
+				p.names = append(p.names, name)
+			}
+		}
+	}
+	if len(p.names) == 0 {
+		return p.run()
+	}
+
+	recent, err := p.screenProps.Params.Get(p.key())
+	if err != nil {
+		slog.Error("Params.prompt", "error", err)
+	}
+
+	p.values = make([]history.ParamValue, len(p.names))
+	fields := make([]huh.Field, 0, 2*len(p.names))
+	for i, name := range p.names {
+		p.values[i] = history.ParamValue{Type: typeText}
+		if v, ok := recent[name]; ok {
+			p.values[i] = v
+		}
+
+		label := name + " as "
+		if !strings.HasPrefix(name, "$") && !strings.HasPrefix(name, "?") {
+			label = ":" + label
+		}
+
+		v := &p.values[i]
+		fields = append(fields,
+			huh.NewSelect[string]().
+				Title(label).
+				Options(huh.NewOptions(typeText, typeNumber, typeBoolean, typeNull)...).
+				Inline(true).
+				Value(&v.Type),
+			huh.NewInput().
+				Placeholder("value").
+				Value(&v.Text).
+				Validate(func(text string) error {
+					_, err := parse(v.Type, text)
+					return err
+				}),
+		)
+	}
+
+	p.form = huh.NewForm(huh.NewGroup(fields...)).
+		WithShowHelp(false).
+		WithWidth(p.formWidth())
+
+	return tea.Batch(
+		p.form.Init(),
+		p.screenProps.MessageManager.NewChangeScreenCmd(message.ScreenNameParams),
+	)
+}
+
+// run runs the statements with the values given for their parameters, and
+// keeps the values to offer them again.
+func (p *Params) run() tea.Cmd {
+	session := p.screenProps.SessionManager.Active()
+	if session == nil {
+		return message.NewStatusUpdateCmd("NO CONNECTION", "Connect to a database to run queries")
+	}
+	dialect := session.Database.Dialect()
+
+	recent := make(map[string]history.ParamValue, len(p.names))
+	args := make(map[string]any, len(p.names))
+	for i, name := range p.names {
+		recent[name] = p.values[i]
+		// The form has validated the values.
+		args[name], _ = parse(p.values[i].Type, p.values[i].Text)
+	}
+	if len(recent) > 0 {
+		// Failing to keep the values does not stop the statements from
+		// running.
+		err := p.screenProps.Params.Set(p.key(), recent)
+		if err != nil {
+			slog.Error("Params.run", "error", err)
+		}
+	}
+
+	statements := make([]database.Statement, len(p.statements))
+	for i, stmt := range p.statements {
+		statements[i] = stmt
+		statements[i].SQL, statements[i].Args = dialect.Bind(stmt.SQL, args)
+	}
+
+	return p.screenProps.MessageManager.NewExecuteScriptCmd(statements)
+}
+
+// key is the key the values given for the statements are kept under.
+func (p *Params) key() string {
+	queries := make([]string, len(p.statements))
+	for i, stmt := range p.statements {
+		queries[i] = stmt.SQL
+	}
+
+	return strings.Join(queries, ";\n")
+}
+
+// parse converts the text typed for a parameter to a value of the given
+// type.
+func parse(kind, text string) (any, error) {
+	switch kind {
+	case typeNumber:
+		text = strings.TrimSpace(text)
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, nil
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, nil
+		}
+		return nil, errors.New("not a number")
+	case typeBoolean:
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, errors.New("not true or false")
+		}
+		return b, nil
+	case typeNull:
+		return nil, nil
+	}
+
+	return text, nil
+}
+
+func (p *Params) formWidth() int {
+	return max(p.width*3/5-boxStyle.GetHorizontalFrameSize(), 20)
+}
+
+// View implements Screen.
+func (p *Params) View() string {
+	if p.form == nil {
+		return ""
+	}
+
+	queries := make([]string, len(p.statements))
+	for i, stmt := range p.statements {
+		queries[i] = stmt.SQL
+	}
+	lines := strings.Split(strings.Join(queries, "\n"), "\n")
+	if len(lines) > maxPreviewLines {
+		lines = append(lines[:maxPreviewLines], "…")
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Parameters"),
+		lipgloss.NewStyle().Width(p.formWidth()).MarginBottom(1).Render(common.Highlight(strings.Join(lines, "\n"), "sql")),
+		p.form.View(),
+	)
+
+	return lipgloss.Place(p.width, p.height, lipgloss.Center, lipgloss.Center, boxStyle.Render(content))
+}
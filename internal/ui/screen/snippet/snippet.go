@@ -0,0 +1,165 @@
+package snippet
+
+import (
+	"log/slog"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/config"
+	"github.com/davesavic/lazydb/internal/service/database"
+	"github.com/davesavic/lazydb/internal/service/message"
+	"github.com/davesavic/lazydb/internal/ui/common"
+)
+
+// snippetsPath is the file snippets are read from, next to connections.toml.
+const snippetsPath = "snippets.toml"
+
+var boxStyle = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#FF00FF")).Padding(0, 1)
+
+// Snippets lists the saved queries that can be run on the active session.
+// The parameters of a snippet are asked for before it is run with them bound
+// as arguments.
+type Snippets struct {
+	width       int
+	height      int
+	screenProps *common.ScreenProps
+
+	list list.Model
+}
+
+type listItem struct {
+	snippet config.Snippet
+}
+
+func (l listItem) Title() string {
+	return l.snippet.Name
+}
+
+func (l listItem) Description() string {
+	scope := "global"
+	if l.snippet.Connection != "" {
+		scope = l.snippet.Connection
+	}
+
+	if l.snippet.Description == "" {
+		return scope
+	}
+
+	return scope + " · " + l.snippet.Description
+}
+
+func (l listItem) FilterValue() string {
+	return l.snippet.Name + " " + l.snippet.Description
+}
+
+func NewSnippets(props *common.ScreenProps) *Snippets {
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Snippets"
+	l.SetShowStatusBar(false)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "Run snippet")),
+		}
+	}
+
+	return &Snippets{
+		screenProps: props,
+		list:        l,
+	}
+}
+
+// Init implements Screen.
+func (s *Snippets) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements Screen.
+func (s *Snippets) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+		s.resize()
+		return s, nil
+
+	case message.ShowSnippetsMsg:
+		return s, s.show()
+
+	case tea.KeyMsg:
+		// Cancel clears the filter first, if one has been typed.
+		if key.Matches(msg, s.screenProps.Keymap.Cancel) && s.list.FilterValue() == "" {
+			return s, s.screenProps.MessageManager.NewPreviousScreenCmd()
+		}
+
+		if s.list.FilterState() == list.Filtering {
+			break
+		}
+
+		if msg.String() == "enter" {
+			selected, ok := s.list.SelectedItem().(listItem)
+			if !ok {
+				return s, nil
+			}
+
+			return s, s.run(selected.snippet)
+		}
+	}
+
+	newList, cmd := s.list.Update(msg)
+	s.list = newList
+
+	return s, cmd
+}
+
+// show loads the snippets of the active session's connection and switches
+// to them.
+func (s *Snippets) show() tea.Cmd {
+	snippets, err := s.screenProps.ConfigService.LoadSnippets(snippetsPath, s.screenProps.SessionManager.ActiveName())
+	if err != nil {
+		slog.Error("Snippets.show", "error", err)
+		return s.screenProps.MessageManager.NewErrorCmd(err)
+	}
+	if len(snippets) == 0 {
+		return message.NewStatusUpdateCmd("SNIPPETS", "No snippets, add them to "+snippetsPath)
+	}
+
+	items := make([]list.Item, len(snippets))
+	for i, snippet := range snippets {
+		items[i] = listItem{snippet: snippet}
+	}
+
+	s.list.ResetFilter()
+	s.list.Select(0)
+
+	return tea.Batch(
+		s.list.SetItems(items),
+		s.screenProps.MessageManager.NewChangeScreenCmd(message.ScreenNameSnippets),
+	)
+}
+
+// run closes the snippets and runs snippet on the active session, once the
+// values of its parameters are given.
+func (s *Snippets) run(snippet config.Snippet) tea.Cmd {
+	if s.screenProps.SessionManager.Active() == nil {
+		return message.NewStatusUpdateCmd("NO CONNECTION", "Connect to a database to run snippets")
+	}
+
+	return tea.Sequence(
+		s.screenProps.MessageManager.NewPreviousScreenCmd(),
+		s.screenProps.MessageManager.NewPromptParamsCmd([]database.Statement{{SQL: snippet.Query}}),
+	)
+}
+
+// resize fits the list in a box that covers most of the screen.
+func (s *Snippets) resize() {
+	width := s.width*4/5 - boxStyle.GetHorizontalFrameSize()
+	height := s.height*4/5 - boxStyle.GetVerticalFrameSize()
+	s.list.SetSize(max(width, 0), max(height, 0))
+}
+
+// View implements Screen.
+func (s *Snippets) View() string {
+	return lipgloss.Place(s.width, s.height, lipgloss.Center, lipgloss.Center, boxStyle.Render(s.list.View()))
+}
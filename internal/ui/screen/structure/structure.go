@@ -0,0 +1,254 @@
+package structure
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/davesavic/lazydb/internal/service/database"
+	"github.com/davesavic/lazydb/internal/service/message"
+	"github.com/davesavic/lazydb/internal/ui/common"
+)
+
+var (
+	boxStyle     = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#FF00FF")).Padding(0, 1)
+	titleStyle   = lipgloss.NewStyle().Bold(true).MarginBottom(1)
+	sectionStyle = lipgloss.NewStyle().Bold(true)
+	nameStyle    = lipgloss.NewStyle().Bold(true)
+	faintStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+// Structure shows how a table is defined: its columns, keys, indexes, check
+// constraints and triggers.
+type Structure struct {
+	width       int
+	height      int
+	screenProps *common.ScreenProps
+
+	viewport  viewport.Model
+	title     string
+	structure *database.TableStructure
+}
+
+func NewStructure(props *common.ScreenProps) *Structure {
+	return &Structure{
+		screenProps: props,
+		viewport:    viewport.New(0, 0),
+	}
+}
+
+// Init implements Screen.
+func (s *Structure) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements Screen.
+func (s *Structure) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+		s.resize()
+		return s, nil
+
+	case message.StructureLoadedMsg:
+		if msg.Err != nil {
+			return s, nil
+		}
+
+		s.title = msg.Table
+		if msg.Schema != "" {
+			s.title = msg.Schema + "." + msg.Table
+		}
+		s.structure = msg.Structure
+		s.render()
+		s.viewport.GotoTop()
+
+		return s, s.screenProps.MessageManager.NewChangeScreenCmd(message.ScreenNameStructure)
+
+	case tea.KeyMsg:
+		if key.Matches(msg, s.screenProps.Keymap.Cancel) {
+			return s, s.screenProps.MessageManager.NewPreviousScreenCmd()
+		}
+	}
+
+	var cmd tea.Cmd
+	s.viewport, cmd = s.viewport.Update(msg)
+
+	return s, cmd
+}
+
+// resize fits the structure in a box that covers most of the screen.
+func (s *Structure) resize() {
+	s.viewport.Width = max(s.width*4/5-boxStyle.GetHorizontalFrameSize(), 0)
+	s.viewport.Height = max(s.height*4/5-boxStyle.GetVerticalFrameSize()-titleStyle.GetVerticalFrameSize()-1, 0)
+	s.render()
+}
+
+// render lays the structure out in sections.
+func (s *Structure) render() {
+	st := s.structure
+	if st == nil {
+		return
+	}
+
+	foreign := make(map[string]bool)
+	for _, fk := range st.ForeignKeys {
+		for _, column := range fk.Columns {
+			foreign[column] = true
+		}
+	}
+
+	columns := [][]string{{"", "Name", "Type", "Null", "Default", "Comment"}}
+	for _, col := range st.Columns {
+		var keys []string
+		if slices.Contains(st.PrimaryKey, col.Name) {
+			keys = append(keys, "PK")
+		}
+		if foreign[col.Name] {
+			keys = append(keys, "FK")
+		}
+
+		null := "not null"
+		if col.Nullable {
+			null = "null"
+		}
+
+		columns = append(columns, []string{strings.Join(keys, " "), col.Name, col.Type, null, col.Default, col.Comment})
+	}
+
+	var primaryKey []string
+	if len(st.PrimaryKey) > 0 {
+		primaryKey = append(primaryKey, "("+strings.Join(st.PrimaryKey, ", ")+")")
+	}
+
+	var foreignKeys []string
+	for _, fk := range st.ForeignKeys {
+		foreignKeys = append(foreignKeys, foreignKey(fk))
+	}
+
+	var indexes []string
+	for _, index := range st.Indexes {
+		name := nameStyle.Render(index.Name)
+		switch {
+		case index.Primary:
+			name += " " + faintStyle.Render("primary")
+		case index.Unique:
+			name += " " + faintStyle.Render("unique")
+		}
+		indexes = append(indexes, name+"\n"+s.sql(index.Definition))
+	}
+
+	var checks []string
+	for _, check := range st.Checks {
+		checks = append(checks, named(check.Name, s.sql(check.Expression)))
+	}
+
+	var triggers []string
+	for _, trigger := range st.Triggers {
+		triggers = append(triggers, named(trigger.Name, s.sql(trigger.Definition)))
+	}
+
+	s.viewport.SetContent(strings.Join([]string{
+		section("Columns", []string{grid(columns)}),
+		section("Primary key", primaryKey),
+		section("Foreign keys", foreignKeys),
+		section("Indexes", indexes),
+		section("Check constraints", checks),
+		section("Triggers", triggers),
+	}, "\n\n"))
+}
+
+// sql highlights an SQL definition, wrapped to fit the box.
+func (s *Structure) sql(definition string) string {
+	return lipgloss.NewStyle().PaddingLeft(2).Width(max(s.viewport.Width, 1)).Render(common.Highlight(definition, "sql"))
+}
+
+// foreignKey describes a foreign key on a line, leaving out the actions
+// that are the default.
+func foreignKey(fk database.ForeignKey) string {
+	ref := fk.RefTable
+	if fk.RefSchema != "" {
+		ref = fk.RefSchema + "." + fk.RefTable
+	}
+	if len(fk.RefColumns) > 0 {
+		ref += " (" + strings.Join(fk.RefColumns, ", ") + ")"
+	}
+
+	line := "(" + strings.Join(fk.Columns, ", ") + ") → " + ref
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		line += " ON UPDATE " + fk.OnUpdate
+	}
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		line += " ON DELETE " + fk.OnDelete
+	}
+
+	if fk.Name == "" {
+		return line
+	}
+
+	return nameStyle.Render(fk.Name) + " " + line
+}
+
+// named puts a name above a definition, if there is one.
+func named(name, definition string) string {
+	if name == "" {
+		return definition
+	}
+
+	return nameStyle.Render(name) + "\n" + definition
+}
+
+// section renders a titled section of entries, noting when it has none.
+func section(title string, entries []string) string {
+	if len(entries) == 0 {
+		entries = []string{faintStyle.Render("none")}
+	}
+
+	return sectionStyle.Render(title) + "\n" + strings.Join(entries, "\n")
+}
+
+// grid aligns rows in columns, with the first row as a faint header.
+func grid(rows [][]string) string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = cell + strings.Repeat(" ", widths[i]-lipgloss.Width(cell))
+		}
+
+		line := strings.TrimRight(strings.Join(cells, "  "), " ")
+		if r == 0 {
+			line = faintStyle.Render(line)
+		}
+		lines[r] = line
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// View implements Screen.
+func (s *Structure) View() string {
+	title := fmt.Sprintf("Structure of %s (%3.f%%)", s.title, s.viewport.ScrollPercent()*100)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(title),
+		s.viewport.View(),
+	)
+
+	return lipgloss.Place(s.width, s.height, lipgloss.Center, lipgloss.Center, boxStyle.Render(content))
+}
@@ -0,0 +1,252 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The gRPC contract is built from well known protobuf types so that plugins
+// need no generated code:
+//
+//	service Database {
+//	  rpc Connect(google.protobuf.Struct) returns (google.protobuf.Empty);
+//	  rpc GetTables(google.protobuf.Empty) returns (google.protobuf.ListValue);
+//	  rpc Run(google.protobuf.Struct) returns (google.protobuf.Struct);
+//	  rpc Close(google.protobuf.Empty) returns (google.protobuf.Empty);
+//	}
+//
+// Connect receives the Config fields keyed by snake case name. Run receives a
+// struct with the "query" string and an "args" list, and returns a struct with
+// a "columns" list of strings and a "rows" list of lists.
+const serviceName = "lazydb.plugin.v1.Database"
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Connect", Handler: unaryHandler(func(s *grpcServer, ctx context.Context, in *structpb.Struct) (*emptypb.Empty, error) {
+			return s.Connect(ctx, in)
+		})},
+		{MethodName: "GetTables", Handler: unaryHandler(func(s *grpcServer, ctx context.Context, in *emptypb.Empty) (*structpb.ListValue, error) {
+			return s.GetTables(ctx, in)
+		})},
+		{MethodName: "Run", Handler: unaryHandler(func(s *grpcServer, ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+			return s.Run(ctx, in)
+		})},
+		{MethodName: "Close", Handler: unaryHandler(func(s *grpcServer, ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
+			return s.Close(ctx, in)
+		})},
+	},
+}
+
+// unaryHandler adapts a typed server method to a grpc.MethodDesc handler.
+func unaryHandler[Req any, Resp any, PReq interface{ *Req }](call func(*grpcServer, context.Context, PReq) (Resp, error)) grpc.MethodHandler {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := PReq(new(Req))
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+
+		s := srv.(*grpcServer)
+		if interceptor == nil {
+			return call(s, ctx, in)
+		}
+
+		return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv}, func(ctx context.Context, req any) (any, error) {
+			return call(s, ctx, req.(PReq))
+		})
+	}
+}
+
+// grpcServer runs on the plugin side and forwards calls to the Database.
+type grpcServer struct {
+	impl Database
+}
+
+func (s *grpcServer) Connect(_ context.Context, in *structpb.Struct) (*emptypb.Empty, error) {
+	fields := in.GetFields()
+	cfg := Config{
+		Host:     fields["host"].GetStringValue(),
+		Port:     fields["port"].GetStringValue(),
+		Database: fields["database"].GetStringValue(),
+		User:     fields["user"].GetStringValue(),
+		Password: fields["password"].GetStringValue(),
+		Path:     fields["path"].GetStringValue(),
+		ReadOnly: fields["read_only"].GetBoolValue(),
+	}
+
+	return &emptypb.Empty{}, s.impl.Connect(cfg)
+}
+
+func (s *grpcServer) GetTables(_ context.Context, _ *emptypb.Empty) (*structpb.ListValue, error) {
+	tables, err := s.impl.GetTables()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(tables))
+	for i, t := range tables {
+		values[i] = t
+	}
+
+	return structpb.NewList(values)
+}
+
+func (s *grpcServer) Run(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	fields := in.GetFields()
+	result, err := s.impl.Run(ctx, fields["query"].GetStringValue(), fields["args"].GetListValue().AsSlice())
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]any, len(result.Columns))
+	for i, c := range result.Columns {
+		columns[i] = c
+	}
+
+	rows := make([]any, len(result.Rows))
+	for i, row := range result.Rows {
+		values := make([]any, len(row))
+		for j, v := range row {
+			values[j] = wireValue(v)
+		}
+		rows[i] = values
+	}
+
+	return structpb.NewStruct(map[string]any{
+		"columns": columns,
+		"rows":    rows,
+	})
+}
+
+func (s *grpcServer) Close(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, s.impl.Close()
+}
+
+// wireValue converts v into a value structpb can encode.
+func wireValue(v any) any {
+	switch v := v.(type) {
+	case nil, bool, string, []byte,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// wireArg converts a bind argument into a value structpb can encode. Numbers
+// are sent as doubles, so integers that a double cannot hold exactly are sent
+// as strings instead.
+func wireArg(v any) any {
+	const exact = 1 << 53
+
+	switch n := v.(type) {
+	case int64:
+		if n > exact || n < -exact {
+			return strconv.FormatInt(n, 10)
+		}
+	case uint64:
+		if n > exact {
+			return strconv.FormatUint(n, 10)
+		}
+	}
+
+	return wireValue(v)
+}
+
+var _ Database = (*grpcClient)(nil)
+
+// grpcClient runs inside lazydb and implements Database by calling the plugin.
+type grpcClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *grpcClient) invoke(ctx context.Context, method string, in, out any) error {
+	err := c.conn.Invoke(ctx, "/"+serviceName+"/"+method, in, out)
+	if s, ok := status.FromError(err); ok && err != nil {
+		// Strip the gRPC framing so plugin errors read like driver errors.
+		return errors.New(s.Message())
+	}
+
+	return err
+}
+
+func (c *grpcClient) Connect(cfg Config) error {
+	in, err := structpb.NewStruct(map[string]any{
+		"host":      cfg.Host,
+		"port":      cfg.Port,
+		"database":  cfg.Database,
+		"user":      cfg.User,
+		"password":  cfg.Password,
+		"path":      cfg.Path,
+		"read_only": cfg.ReadOnly,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.invoke(context.Background(), "Connect", in, &emptypb.Empty{})
+}
+
+func (c *grpcClient) GetTables() ([]string, error) {
+	out := &structpb.ListValue{}
+	if err := c.invoke(context.Background(), "GetTables", &emptypb.Empty{}, out); err != nil {
+		return nil, err
+	}
+
+	tables := make([]string, 0, len(out.GetValues()))
+	for _, v := range out.GetValues() {
+		tables = append(tables, v.GetStringValue())
+	}
+
+	return tables, nil
+}
+
+func (c *grpcClient) Run(ctx context.Context, query string, args []any) (*Result, error) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = wireArg(arg)
+	}
+
+	in, err := structpb.NewStruct(map[string]any{
+		"query": query,
+		"args":  values,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not encode arguments: %w", err)
+	}
+
+	out := &structpb.Struct{}
+	if err := c.invoke(ctx, "Run", in, out); err != nil {
+		return nil, err
+	}
+
+	fields := out.GetFields()
+	result := &Result{}
+
+	for _, v := range fields["columns"].GetListValue().GetValues() {
+		result.Columns = append(result.Columns, v.GetStringValue())
+	}
+
+	for _, row := range fields["rows"].GetListValue().GetValues() {
+		values := row.GetListValue().AsSlice()
+		result.Rows = append(result.Rows, values)
+	}
+
+	return result, nil
+}
+
+func (c *grpcClient) Close() error {
+	return c.invoke(context.Background(), "Close", &emptypb.Empty{}, &emptypb.Empty{})
+}
@@ -0,0 +1,92 @@
+// Package plugin lets lazydb browse databases served by an external plugin
+// binary. A plugin implements Database and calls Serve from its main function.
+// lazydb discovers plugin binaries named "lazydb-plugin-<type>" in its plugins
+// directory and uses them for connections whose type is "<type>".
+package plugin
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+// BinaryPrefix is the file name prefix lazydb looks for in its plugins directory.
+const BinaryPrefix = "lazydb-plugin-"
+
+// Name is the name the database plugin is dispensed under.
+const Name = "database"
+
+// Handshake is shared by lazydb and its plugins to make sure a binary is
+// actually a lazydb plugin speaking a compatible protocol version. Version 2
+// passes bind arguments to Run.
+var Handshake = plugin.HandshakeConfig{
+	ProtocolVersion:  2,
+	MagicCookieKey:   "LAZYDB_PLUGIN",
+	MagicCookieValue: "database",
+}
+
+// Config holds the connection settings from connections.toml.
+type Config struct {
+	Host     string
+	Port     string
+	Database string
+	User     string
+	Password string
+	Path     string
+	// ReadOnly is set for connections that must not change the database.
+	ReadOnly bool
+}
+
+// Result is the outcome of running a query. Values in Rows are positional and
+// must be nil, a bool, a number, a string or a []byte; other values are sent
+// as their string representation.
+type Result struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Database is the interface implemented by plugins. It mirrors lazydb's own
+// database integration.
+type Database interface {
+	Connect(cfg Config) error
+	GetTables() ([]string, error)
+	// Run executes query with args bound to its $1, $2, ... placeholders.
+	// Arguments are nil, a bool, a float64 or a string; integers too large
+	// for a float64 to hold exactly arrive as strings. ctx is cancelled when
+	// the user cancels the query.
+	Run(ctx context.Context, query string, args []any) (*Result, error)
+	Close() error
+}
+
+// Serve runs the plugin, serving db to lazydb over gRPC. It blocks until
+// lazydb disconnects.
+func Serve(db Database) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: plugin.PluginSet{
+			Name: &GRPCPlugin{Impl: db},
+		},
+		GRPCServer: plugin.DefaultGRPCServer,
+	})
+}
+
+var _ plugin.GRPCPlugin = (*GRPCPlugin)(nil)
+
+// GRPCPlugin is the go-plugin definition of a database plugin. Impl is only
+// set on the plugin side.
+type GRPCPlugin struct {
+	plugin.NetRPCUnsupportedPlugin
+	Impl Database
+}
+
+// GRPCServer implements plugin.GRPCPlugin.
+func (p *GRPCPlugin) GRPCServer(_ *plugin.GRPCBroker, s *grpc.Server) error {
+	s.RegisterService(&serviceDesc, &grpcServer{impl: p.Impl})
+	return nil
+}
+
+// GRPCClient implements plugin.GRPCPlugin.
+func (p *GRPCPlugin) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &grpcClient{conn: c}, nil
+}